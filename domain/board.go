@@ -9,15 +9,32 @@ type Board struct {
 	cells [][]*Block
 }
 
+// BoardSize is the size of a board in blocks, including its grey border
+type BoardSize struct {
+	Width, Height int
+}
+
+// board variants
+var (
+	StandardBoard = BoardSize{Width: BoardWidth, Height: BoardHeight}
+	NarrowBoard   = BoardSize{Width: 10, Height: BoardHeight}
+	WideBoard     = BoardSize{Width: 16, Height: BoardHeight}
+	TallBoard     = BoardSize{Width: BoardWidth, Height: 28}
+)
+
 func NewBoard() Board {
+	return NewBoardWithSize(StandardBoard)
+}
+
+func NewBoardWithSize(size BoardSize) Board {
 
 	b := Board{}
 	// fill with blank cells
-	b.cells = make([][]*Block, BoardWidth)
+	b.cells = make([][]*Block, size.Width)
 
-	for x := 0; x < BoardWidth; x++ {
-		b.cells[x] = make([]*Block, BoardHeight)
-		for y := 0; y < BoardHeight; y++ {
+	for x := 0; x < size.Width; x++ {
+		b.cells[x] = make([]*Block, size.Height)
+		for y := 0; y < size.Height; y++ {
 			b.cells[x][y] = &Block{X: x, Y: y, Colour: Empty}
 		}
 	}
@@ -25,27 +42,58 @@ func NewBoard() Board {
 	return b
 }
 
+// Size returns the size of the board in blocks
+func (b *Board) Size() BoardSize {
+	if len(b.cells) == 0 {
+		return BoardSize{}
+	}
+	return BoardSize{Width: len(b.cells), Height: len(b.cells[0])}
+}
+
+// copy returns a board with the same blocks
+func (b *Board) copy() Board {
+	board := NewBoardWithSize(b.Size())
+	for x, column := range b.cells {
+		for y, block := range column {
+			board.cells[x][y].Colour = block.Colour
+		}
+	}
+	return board
+}
+
 func (b *Board) reset() {
+	size := b.Size()
+
 	// add grey surrounding blocks
 	y := 0
-	for x := 0; x < BoardWidth; x++ {
+	for x := 0; x < size.Width; x++ {
 		b.cells[x][y].Colour = Grey
 	}
-	y = BoardHeight - 1
-	for x := 0; x < BoardWidth; x++ {
+	y = size.Height - 1
+	for x := 0; x < size.Width; x++ {
 		b.cells[x][y].Colour = Grey
 	}
 	x := 0
-	for y := 0; y < BoardHeight; y++ {
+	for y := 0; y < size.Height; y++ {
 		b.cells[x][y].Colour = Grey
 	}
 
-	x = BoardWidth - 1
-	for y := 0; y < BoardHeight; y++ {
+	x = size.Width - 1
+	for y := 0; y < size.Height; y++ {
 		b.cells[x][y].Colour = Grey
 	}
 }
 
+// clearStack empties every cell inside the grey border
+func (b *Board) clearStack() {
+	size := b.Size()
+	for x := 1; x < size.Width-1; x++ {
+		for y := 1; y < size.Height-1; y++ {
+			b.cells[x][y].Colour = Empty
+		}
+	}
+}
+
 func (b *Board) canPlayerFitAt(player *Player, x, y int) bool {
 	/*
 	   Check if player's shape can move down one row
@@ -56,10 +104,11 @@ func (b *Board) canPlayerFitAt(player *Player, x, y int) bool {
 		return false
 	}
 
-	if x < 0 || x > BoardWidth-1 {
+	size := b.Size()
+	if x < 0 || x > size.Width-1 {
 		return false // out of bounds
 	}
-	if y < 0 || y > BoardHeight-1 {
+	if y < 0 || y > size.Height-1 {
 		return false // out of bounds
 	}
 
@@ -70,7 +119,8 @@ func (b *Board) canPlayerFitAt(player *Player, x, y int) bool {
 		blockX := x + block.X
 		blockY := y + block.Y
 
-		if b.cells[blockX][blockY].Colour != Empty {
+		// blocks kicked above the top of the board don't fit
+		if b.isFilled(blockX, blockY) {
 			return false
 		}
 	}
@@ -78,6 +128,15 @@ func (b *Board) canPlayerFitAt(player *Player, x, y int) bool {
 	return true
 }
 
+// isFilled returns true if a cell is not empty,
+// cells outside the board count as filled
+func (b *Board) isFilled(x, y int) bool {
+	if x < 0 || x > len(b.cells)-1 || y < 0 || y > len(b.cells[x])-1 {
+		return true
+	}
+	return b.cells[x][y].Colour != Empty
+}
+
 func (b *Board) addShapeToBoard(player *Player) {
 	/*
 		Shape has collided so add to the permanent board
@@ -100,12 +159,14 @@ func (b *Board) addShapeToBoard(player *Player) {
 	}
 }
 
-func (b *Board) checkCompleteRows() int {
+func (b *Board) checkCompleteRows() []int {
 	/*
-		Check if there are any complete rows
+		Check if there are any complete rows,
+		returns the rows destroyed from bottom to top
 	*/
 
 	fullRows := make(map[int]bool)
+	completeRows := make([]int, 0)
 
 	// remember to ignore first and last grey rows
 	boardWidth := len(b.cells)
@@ -124,6 +185,7 @@ func (b *Board) checkCompleteRows() int {
 		if rowFull {
 			// add full row to list
 			fullRows[y] = true
+			completeRows = append(completeRows, y)
 		}
 	}
 
@@ -131,7 +193,7 @@ func (b *Board) checkCompleteRows() int {
 		b.destroyRows(fullRows)
 	}
 
-	return len(fullRows)
+	return completeRows
 }
 
 func (b *Board) destroyRows(rows map[int]bool) {
@@ -144,7 +206,7 @@ func (b *Board) destroyRows(rows map[int]bool) {
 	   and the the board must be refilled
 	*/
 
-	boardHeight := len(b.cells)
+	boardHeight := len(b.cells[0])
 	for y := boardHeight - 1; y >= 1; y-- {
 		if _, ok := rows[y]; ok {
 			//this is a row to destroy
@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestNewBoard(t *testing.T) {
 
@@ -21,3 +24,80 @@ func TestNewBoard(t *testing.T) {
 	}
 
 }
+
+func TestBoardSizes(t *testing.T) {
+
+	for _, size := range []BoardSize{StandardBoard, NarrowBoard, WideBoard, TallBoard} {
+		board := NewBoardWithSize(size)
+		board.reset()
+
+		if board.Size() != size {
+			t.Errorf("Expected size: %v received: %v", size, board.Size())
+		}
+
+		// grey border surrounds the board
+		corners := []Block{
+			*board.cells[0][0],
+			*board.cells[size.Width-1][0],
+			*board.cells[0][size.Height-1],
+			*board.cells[size.Width-1][size.Height-1],
+		}
+		for _, corner := range corners {
+			if corner.Colour != Grey {
+				t.Errorf("Expected grey corner at %d,%d on board %v", corner.X, corner.Y, size)
+			}
+		}
+		if board.cells[1][1].Colour != Empty {
+			t.Errorf("Expected empty cell inside board %v", size)
+		}
+	}
+}
+
+func TestCheckCompleteRows(t *testing.T) {
+
+	board := NewBoard()
+	board.reset()
+
+	fillRow(&board, 1)
+	fillRow(&board, 2, 5)
+	fillRow(&board, 3)
+	board.cells[5][4].Colour = Blue
+
+	rows := board.checkCompleteRows()
+
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected rows: %v received: %v", expected, rows)
+	}
+
+	// remaining rows move down
+	if board.cells[5][1].Colour != Empty || board.cells[4][1].Colour != Red {
+		t.Error("Expected partial row to move to bottom")
+	}
+	if board.cells[5][2].Colour != Blue {
+		t.Error("Expected single block to move down two rows")
+	}
+}
+
+func TestCheckCompleteRowsHigherThanBoardWidth(t *testing.T) {
+
+	for _, size := range []BoardSize{NarrowBoard, TallBoard} {
+		board := NewBoardWithSize(size)
+		board.reset()
+
+		y := size.Width + 2
+		fillRow(&board, y)
+		board.cells[3][y+1].Colour = Blue
+
+		rows := board.checkCompleteRows()
+		if expected := []int{y}; !reflect.DeepEqual(rows, expected) {
+			t.Errorf("Expected board %v rows: %v received: %v", size, expected, rows)
+		}
+		if board.cells[3][y].Colour != Blue || board.cells[4][y].Colour != Empty {
+			t.Errorf("Expected board %v row %d to be removed and the block above to move down", size, y)
+		}
+		if rows := board.checkCompleteRows(); len(rows) != 0 {
+			t.Errorf("Expected board %v rows to be counted once received: %v", size, rows)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Clock tells the real time game loop what time it is.
+// Tests can inject their own clock to control the passing of time
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (s systemClock) Now() time.Time {
+	return time.Now()
+}
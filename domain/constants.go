@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 var GameTitle = "Teletris"
 
 // Board constants
@@ -10,29 +12,70 @@ const (
 	BoardOffsetY    = 32
 	BlockPixels     = 40
 	NextBlockPixels = 20
+	NextOffsetX     = 40
 	NextOffsetY     = 35
+	HoldOffsetX     = 40
+	GhostAlpha      = 80 // opacity of ghost shape out of 255
 )
 
 var ArrowPixels = 64
-var InfoPanelWidth = (BoardWidth - 1) * BlockPixels
+
+// touch gestures
+const (
+	FlickPixels   = 3 * BlockPixels // minimum distance of a downward flick
+	FlickDuration = 250             // maximum duration of a flick in milliseconds
+	QuitDuration  = 2000            // milliseconds to hold still to end a game that can't be lost
+)
+
+// menu
+const (
+	MenuButtonHeight  = 40
+	MenuButtonSpacing = 100
+)
+
+// high score table
+const (
+	LettersWidth        = 30
+	LettersHeight       = 40
+	MaxRankDigits       = 2
+	HighScoreRowSpacing = 60
+	HighScoreOffsetY    = 200 // first row distance from the top of the screen
+	HighScoreButtonsY   = 120
+	CursorBlinkFrames   = 20
+)
 
 // score constants
 const (
-	DigitsWidth       = 30
-	DigitsHeight      = 40
-	AudioButtonWidth  = 40
-	AudioButtonHeight = 40
-	MaxScoreDigits    = 6
-	MaxLevelDigits    = 2
-	ScorePerRow       = 5
+	DigitsWidth         = 30
+	DigitsHeight        = 40
+	AudioButtonWidth    = 40
+	AudioButtonHeight   = 40
+	MaxScoreDigits      = 6
+	MaxScore            = 999999 // largest score the digits can show
+	MaxLevelDigits      = 2
+	MaxStreakDigits     = 2
+	MaxLinesDigits      = 3
+	TimerSeparatorWidth = 10
+	UltraTimerOffsetX   = 205 // timer label distance from the right of the screen
+	SoftDropScorePerRow = 1
+	HardDropScorePerRow = 2
+	ComboScore          = 50 // per combo per level
 )
 
 // speed
 const (
-	BlockStartSpeed    = 500 // Speed blocks fall in milliseconds
-	KeyRepeat          = 150 // Key repeat in milliseconds
 	RowsPerLevel       = 5   // increase level every X rows
-	LevelSpeedIncrease = 50
+	SoftDropSpeed      = 20  // soft drop falls X times faster
+	LockDelayFrames    = 30  // frames a resting shape waits before locking
+	MaxLockResets      = 15  // moves allowed to restart the lock delay
+	GravityDenominator = 256 // gravity is measured in 1/256 rows per frame
+	ZenGravity         = 8   // fixed zen mode gravity, a row every 32 frames
+)
+
+// game loop
+const (
+	FramesPerSecond = 60
+	FrameDuration   = time.Second / FramesPerSecond
 )
 
 //Block colours
@@ -72,6 +115,101 @@ const (
 	T
 )
 
+// Rotation states named after the SRS rotation system,
+// 0 is upright, R and L are turned right and left, 2 is upside down
+type RotationState int
+
+const (
+	Rotation0 RotationState = iota
+	RotationR
+	Rotation2
+	RotationL
+)
+
+// T-spins are detected when a T shape locks straight after rotating
+type TSpin int
+
+const (
+	NoTSpin TSpin = iota
+	MiniTSpin
+	FullTSpin
+)
+
+type RandomizerType int
+
+const (
+	UniformRandomizer RandomizerType = iota
+	BagRandomizer
+	HistoryRandomizer
+)
+
+// history randomizer rerolls
+const HistoryRetries = 6
+
+type Command int
+
+const (
+	MoveLeftCommand Command = iota
+	MoveRightCommand
+	MoveDownCommand
+	RotateCommand
+	RotateCCWCommand
+	Rotate180Command
+	HardDropCommand
+	HoldCommand
+	SoftDropOnCommand
+	SoftDropOffCommand
+	EndGameCommand
+)
+
+type GameMode int
+
+const (
+	MarathonMode GameMode = iota
+	ZenMode
+	SprintMode
+	UltraMode
+)
+
+// modes
+const (
+	SprintLines           = 40 // rows to clear in a sprint
+	UltraDuration         = 3 * time.Minute
+	ShortUltraDuration    = 2 * time.Minute
+	MaxLeaderboardEntries = 10
+)
+
+// high scores
+const (
+	HighScoresFile    = "teletris_scores.json"
+	HighScoresVersion = 3 // bump when the stored format changes
+	NameLength        = 3 // initials entered for a high score
+)
+
+// replays
+const (
+	ReplayFile     = "teletris_last.replay"
+	ReplayVersion  = 2                   // bump when the recorded format changes
+	KeyframeFrames = 5 * FramesPerSecond // frames between replay keyframes
+	SeekFrames     = 5 * FramesPerSecond // frames skipped by each seek
+	MinReplaySpeed = 0.25
+	MaxReplaySpeed = 8
+)
+
+// next queue
+const (
+	DefaultPreviews   = 3
+	MaxPreviews       = 6
+	QueueShrinkPixels = 2 // each shape further down the next queue is smaller
+	QueueOffsetX      = 4 // gap between the next queue and the right of the screen
+)
+
+// saved games
+const (
+	StateFile    = "teletris_game.json"
+	StateVersion = 2 // bump when the saved game format changes
+)
+
 type GameState int
 
 const (
@@ -79,6 +217,7 @@ const (
 	Playing
 	Suspended
 	GameOver
+	Finished // game reached its goal
 )
 
 type PlayerState int
@@ -91,9 +230,16 @@ const (
 type GameEvent int
 
 const (
-	BlockDownEvent = iota
+	BlockDownEvent GameEvent = iota // shape locked onto the board
 	RowsCompleteEvent
 	LevelUpEvent
+	ShapeSpawnedEvent
+	GameOverEvent
+	StateChangedEvent
+	ShapeHeldEvent
+	TSpinEvent        // T shape locked with a T-spin
+	StackClearedEvent // stack cleared to make room instead of topping out
+	GameCompleteEvent // game reached its goal
 )
 
 type Alignment int
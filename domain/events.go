@@ -0,0 +1,93 @@
+package domain
+
+// Event describes something that happened during a game
+type Event struct {
+	Type  GameEvent
+	Frame int
+
+	// ShapeSpawnedEvent, ShapeHeldEvent
+	ShapeType ShapeType
+	// BlockDownEvent
+	Lock LockResult
+	// RowsCompleteEvent, board rows cleared from bottom to top
+	Rows []int
+	// LevelUpEvent
+	Level int
+	// StateChangedEvent
+	State         GameState
+	PreviousState GameState
+}
+
+// EventListener is notified of events raised by a game
+type EventListener interface {
+	OnGameEvent(event Event)
+}
+
+// EventListenerFunc adapts a function to an EventListener
+type EventListenerFunc func(event Event)
+
+func (f EventListenerFunc) OnGameEvent(event Event) {
+	f(event)
+}
+
+type subscription struct {
+	id       int
+	listener EventListener
+}
+
+// Subscribe adds a listener to be notified of game events.
+// Listeners are called without the game locked so they may call back
+// into the game. The returned func removes the listener again
+func (g *Game) Subscribe(listener EventListener) func() {
+	g.mutex.Lock()
+	defer g.unlock()
+
+	g.lastSubscription++
+	id := g.lastSubscription
+	g.subscriptions = append(g.subscriptions, subscription{id: id, listener: listener})
+
+	return func() {
+		g.mutex.Lock()
+		defer g.unlock()
+
+		for i, sub := range g.subscriptions {
+			if sub.id == id {
+				g.subscriptions = append(g.subscriptions[:i:i], g.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// raise queues an event to be sent to listeners once the game is unlocked
+func (g *Game) raise(event Event) {
+	event.Frame = g.frame
+	g.events = append(g.events, event)
+}
+
+// unlock releases the game mutex and sends any raised events to listeners.
+// Only one goroutine sends events at a time so listeners always see them
+// in the order they were raised
+func (g *Game) unlock() {
+	if g.dispatching || len(g.events) == 0 {
+		g.mutex.Unlock()
+		return
+	}
+
+	g.dispatching = true
+	for len(g.events) > 0 {
+		events := g.events
+		g.events = nil
+		subscriptions := g.subscriptions
+
+		g.mutex.Unlock()
+		for _, event := range events {
+			for _, sub := range subscriptions {
+				sub.listener.OnGameEvent(event)
+			}
+		}
+		g.mutex.Lock()
+	}
+	g.dispatching = false
+	g.mutex.Unlock()
+}
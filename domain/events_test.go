@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fillRow fills a board row leaving gaps at the x positions passed
+func fillRow(board *Board, y int, gaps ...int) {
+	for x := 1; x < len(board.cells)-1; x++ {
+		board.cells[x][y].Colour = Red
+	}
+	for _, x := range gaps {
+		board.cells[x][y].Colour = Empty
+	}
+}
+
+func TestRowsCompleteEvents(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 3)
+
+	events := make([]Event, 0)
+	unsubscribe := game.Subscribe(EventListenerFunc(func(event Event) {
+		// listeners may call back into the game
+		game.GetState()
+		events = append(events, event)
+	}))
+
+	// drop a flat bar into the gap of an almost complete row
+	fillRow(&game.board, 1, 1, 2, 3, 4)
+	game.Player.shape = BarShape(Blue)
+	game.Player.X = 1
+	game.Player.Y = 0
+	game.Player.TotalRows = RowsPerLevel - 1
+
+	if game.MoveDown() {
+		t.Fatal("Expected shape to rest on the stack")
+	}
+	for i := 0; i < game.options.LockDelay; i++ {
+		game.Step()
+	}
+
+	expected := []GameEvent{BlockDownEvent, RowsCompleteEvent, LevelUpEvent, ShapeSpawnedEvent}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events received: %v", len(expected), events)
+	}
+	for i, event := range events {
+		if event.Type != expected[i] {
+			t.Errorf("Event %d Expected: %d received: %d", i, expected[i], event.Type)
+		}
+	}
+	if !reflect.DeepEqual(events[1].Rows, []int{1}) {
+		t.Errorf("Expected rows: %v received: %v", []int{1}, events[1].Rows)
+	}
+	if events[2].Level != 2 {
+		t.Errorf("Expected level: %d received: %d", 2, events[2].Level)
+	}
+
+	unsubscribe()
+	game.GameOver()
+	if len(events) != len(expected) {
+		t.Errorf("Expected no events after unsubscribe received: %v", events[len(expected):])
+	}
+}
+
+func TestGameOverEvents(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 3)
+
+	types := make([]GameEvent, 0)
+	game.Subscribe(EventListenerFunc(func(event Event) {
+		types = append(types, event.Type)
+		if event.Type == StateChangedEvent && event.State != GameOver {
+			t.Errorf("Expected state: %d received: %d", GameOver, event.State)
+		}
+	}))
+
+	game.GameOver()
+
+	expected := []GameEvent{StateChangedEvent, GameOverEvent}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("Expected events: %v received: %v", expected, types)
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"log"
+	"sync"
 	"time"
 
 	"golang.org/x/mobile/asset"
@@ -11,10 +12,84 @@ import (
 type AudioManager struct {
 }
 
-type HighScores struct {
+// GameOptions configure how new games are played
+type GameOptions struct {
+	Mode GameMode
+	// scores count towards high scores
+	Ranked bool
+	// topping out clears the stack instead of ending the game
+	ClearOnTopOut bool
+	// rows to clear to complete the game, 0 plays on until the game is lost
+	LineTarget int
+	// game time played to complete the game, 0 plays on until the game is lost
+	TimeLimit time.Duration
+	// deals the shapes
+	Randomizer RandomizerType
+	// frames a shape can rest on the stack before it locks
+	LockDelay int
+	// times moving or rotating a resting shape restarts the lock delay
+	LockResets int
+	// awards points for each lock
+	Scorer Scorer
+	// speed shapes fall at each level
+	Gravity GravityCurve
+	// size of the board including its grey border
+	BoardSize BoardSize
+	// shapes shown in the next queue, 1 to MaxPreviews
+	Previews int
 }
 
+// DefaultGameOptions returns the options used by NewGame
+func DefaultGameOptions() GameOptions {
+	return GameOptions{
+		Mode:       MarathonMode,
+		Ranked:     true,
+		Randomizer: BagRandomizer,
+		LockDelay:  LockDelayFrames,
+		LockResets: MaxLockResets,
+		Scorer:     GuidelineScorer,
+		Gravity:    GuidelineGravity,
+		BoardSize:  StandardBoard,
+		Previews:   DefaultPreviews,
+	}
+}
+
+// hasGoal returns true for games that end when a goal is reached
+func (o GameOptions) hasGoal() bool {
+	return o.LineTarget > 0 || o.TimeLimit > 0
+}
+
+// LeaderboardKey returns the leaderboard games played with the options rank on
+func (o GameOptions) LeaderboardKey() LeaderboardKey {
+	return LeaderboardKey{Mode: o.Mode, LineTarget: o.LineTarget, TimeLimit: o.TimeLimit}
+}
+
+// ModeOptions returns the options used to play a game mode
+func ModeOptions(mode GameMode) GameOptions {
+	options := DefaultGameOptions()
+	options.Mode = mode
+	switch mode {
+	case ZenMode:
+		// relax, the game can't be lost
+		options.Ranked = false
+		options.ClearOnTopOut = true
+		options.Gravity = flatGravity(ZenGravity, GuidelineGravity.MaxLevel())
+	case SprintMode:
+		// race to clear the line target
+		options.LineTarget = SprintLines
+	case UltraMode:
+		// score as much as possible before time runs out
+		options.TimeLimit = UltraDuration
+	}
+	return options
+}
+
+// Game holds the state of a game of Teletris.
+// Its exported methods are safe to call from multiple goroutines,
+// player input and the real time driver are serialized by a mutex
 type Game struct {
+	mutex       sync.Mutex
+	options     GameOptions
 	state       GameState
 	prevState   GameState
 	board       Board
@@ -22,6 +97,45 @@ type Game struct {
 	audioOn     bool
 	audioPlayer *audio.Player
 	dirty       bool
+	seed        int64
+	randomizer  Randomizer
+
+	// game loop
+	clock       Clock
+	stopRunning chan struct{}
+	frame       int
+	frameTime   time.Duration
+	gravity     int // progress towards the next row in 1/GravityDenominator rows
+	softDrop    bool
+	lockFrames  int
+	lockResets  int
+	lowestY     int
+	rotated     bool // last successful action was a rotation
+	lastKick    int  // index of the kick used by the last rotation
+
+	// scoring
+	softDropRows int
+	hardDropRows int
+	lastLock     LockResult
+
+	// results
+	result         GameResult
+	rank           int // rank of the result on its leaderboard, 0 if it didn't place
+	highScores     HighScores
+	highScoresPath string
+
+	// recording
+	replay     Replay
+	replayPath string
+
+	// saved game
+	statePath string
+
+	// events
+	subscriptions    []subscription
+	lastSubscription int
+	events           []Event
+	dispatching      bool
 }
 
 type Teletris struct {
@@ -31,14 +145,30 @@ type Teletris struct {
 }
 
 func NewGame() *Game {
+	return NewGameWithOptions(DefaultGameOptions())
+}
+
+func NewGameWithOptions(options GameOptions) *Game {
 	g := new(Game)
+	g.options = options
 	g.audioOn = true
-	g.StartMenu()
+	g.clock = systemClock{}
+	g.board = NewBoardWithSize(options.BoardSize)
+	g.board.reset()
+	g.highScores = NewHighScores()
+	g.startMenu()
 	return g
 }
 
 func (g *Game) StartMenu() {
-	g.state = Menu
+	g.mutex.Lock()
+	defer g.unlock()
+	g.startMenu()
+}
+
+func (g *Game) startMenu() {
+	g.stop()
+	g.setState(Menu)
 }
 
 func (g *Game) initAudio() {
@@ -54,12 +184,12 @@ func (g *Game) initAudio() {
 }
 
 func (g *Game) StartGame() {
+	g.mutex.Lock()
+	defer g.unlock()
+
 	// Start a new game
-	g.board = NewBoard()
 	g.initAudio()
-	// init player state
-	g.Player = NewPlayer()
-	g.board.reset()
+	g.newGame()
 	g.audioPlayer.Seek(0)
 	g.audioPlayer.SetVolume(1.0)
 
@@ -69,50 +199,342 @@ func (g *Game) StartGame() {
 		g.audioPlayer.Pause()
 	}
 
-	g.state = Playing
-	g.Player.setNextRandomShape()
+	g.start()
+
+}
+
+// newGame resets the board and player ready for a new game
+func (g *Game) newGame() {
+	g.board = NewBoardWithSize(g.options.BoardSize)
+	// init player state
+	g.Player = NewPlayer(g.nextRandomizer(), g.options.BoardSize, g.options.Previews)
+	g.board.reset()
+
+	g.frame = 0
+	g.frameTime = 0
+	g.result = GameResult{}
+	g.rank = 0
+	g.gravity = 0
+	g.softDrop = false
+	g.softDropRows = 0
+	g.hardDropRows = 0
+	g.lastLock = LockResult{}
+
+	g.setState(Playing)
+	g.startRecording()
 	g.Player.setNextRandomShape()
+	g.newShape()
+}
 
-	go g.run()
+// SetSeed sets the seed used to deal shapes in the next game.
+// A seed of zero picks a new seed from the current time
+func (g *Game) SetSeed(seed int64) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.seed = seed
+}
 
+// SetRandomizer injects the randomizer used to deal shapes in the next game,
+// it takes priority over any seed set with SetSeed
+func (g *Game) SetRandomizer(randomizer Randomizer) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.randomizer = randomizer
+}
+
+// SetMode changes the mode of the next game, keeping the board size,
+// randomizer and next queue length, and keeping a time limit set for a timed mode
+func (g *Game) SetMode(mode GameMode) {
+	g.mutex.Lock()
+	defer g.unlock()
+	previous := g.options
+	g.options = ModeOptions(mode)
+	g.options.BoardSize = previous.BoardSize
+	g.options.Randomizer = previous.Randomizer
+	g.options.Previews = previous.Previews
+	if g.options.TimeLimit > 0 && previous.TimeLimit > 0 {
+		g.options.TimeLimit = previous.TimeLimit
+	}
+}
+
+// Mode returns the mode of the current game
+func (g *Game) Mode() GameMode {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.options.Mode
+}
+
+// Ranked returns true if the current game counts towards high scores
+func (g *Game) Ranked() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.options.Ranked
+}
+
+// SetLineTarget changes the rows to clear to complete the next game,
+// 0 plays on until the game is lost
+func (g *Game) SetLineTarget(rows int) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.options.LineTarget = rows
+}
+
+// LineTarget returns the rows to clear to complete the current game
+func (g *Game) LineTarget() int {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.options.LineTarget
+}
+
+// SetTimeLimit changes the game time played to complete the next game,
+// 0 plays on until the game is lost
+func (g *Game) SetTimeLimit(limit time.Duration) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.options.TimeLimit = limit
+}
+
+// SetBoardSize changes the size of the board used by the next game
+func (g *Game) SetBoardSize(size BoardSize) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.options.BoardSize = size
+}
+
+// SetPreviews sets how many shapes the next queue shows in the next game,
+// between 1 and MaxPreviews
+func (g *Game) SetPreviews(previews int) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.options.Previews = previews
+}
+
+// BoardSize returns the size of the current board
+func (g *Game) BoardSize() BoardSize {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.board.Size()
+}
+
+// Seed returns the seed of the randomizer dealing the current game
+func (g *Game) Seed() int64 {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.Player == nil {
+		return g.seed
+	}
+	return g.Player.randomizer.Seed()
+}
+
+func (g *Game) nextRandomizer() Randomizer {
+	if g.randomizer != nil {
+		// injected randomizers are only used for a single game
+		randomizer := g.randomizer
+		g.randomizer = nil
+		return randomizer
+	}
+
+	seed := g.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return NewRandomizer(g.options.Randomizer, seed)
 }
 
 func (g *Game) SetBoardDirty() {
+	g.mutex.Lock()
+	defer g.unlock()
 	g.dirty = true
 }
 
 func (g *Game) IsBoardDirty() bool {
+	g.mutex.Lock()
+	defer g.unlock()
 	return g.dirty
 }
 
 func (g *Game) CleanBoard() {
+	g.mutex.Lock()
+	defer g.unlock()
 	g.dirty = false
 }
 
 func (g *Game) SuspendGame() {
-	g.ChangeState(Suspended)
+	g.mutex.Lock()
+	defer g.unlock()
+
+	if g.state == Suspended {
+		return
+	}
+	g.stop()
+	g.changeState(Suspended)
 	g.audioPlayer.Pause()
 
+	// the app may be killed while suspended
+	if err := g.saveState(); err != nil {
+		log.Printf("Error saving game: %s", err)
+	}
 }
 
 func (g *Game) ResumeGame() {
+	g.mutex.Lock()
+	defer g.unlock()
+
 	// revert to previous state
-	g.ChangeState(g.prevState)
+	g.changeState(g.prevState)
+	if g.state == Playing && g.audioPlayer == nil {
+		// games restored from disk start without music
+		g.initAudio()
+		g.audioPlayer.SetVolume(1.0)
+	}
 	if g.audioOn {
 		g.audioPlayer.Play()
 	}
 
-	go g.run()
+	if g.state == Playing {
+		g.start()
+	}
 }
 
 func (g *Game) GameOver() {
-	g.state = GameOver
+	g.mutex.Lock()
+	defer g.unlock()
+	g.gameOver()
+}
+
+// EndGame ends the game being played early,
+// it is the only way out of games that can't be lost
+func (g *Game) EndGame() {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return
+	}
+	g.record(EndGameCommand)
+	g.gameOver()
+}
+
+// topOut is called when there is no room for the shape,
+// the game ends unless the mode clears the stack to make room
+func (g *Game) topOut() {
+	if !g.options.ClearOnTopOut {
+		g.gameOver()
+		return
+	}
+	g.board.clearStack()
+	g.raise(Event{Type: StackClearedEvent})
+	g.dirty = true
+}
+
+// complete ends a game that reached its goal
+func (g *Game) complete() {
+	g.result.Completed = true
+	g.end(Finished)
+	g.raise(Event{Type: GameCompleteEvent})
+}
+
+func (g *Game) gameOver() {
+	g.end(GameOver)
+	g.raise(Event{Type: GameOverEvent})
+}
+
+// end stops the game and records its result
+func (g *Game) end(state GameState) {
+	g.stop()
+	g.setState(state)
 	g.audioPlayer.Stop()
 
-	// TODO update high scores
+	if g.recordResult() && g.highScoresPath != "" {
+		if err := g.saveHighScores(); err != nil {
+			log.Printf("Error saving high scores: %s", err)
+		}
+	}
+	// keep the last game so players can send it with bug reports
+	if g.replayPath != "" {
+		if err := g.recording().Write(g.replayPath); err != nil {
+			log.Printf("Error saving replay: %s", err)
+		}
+	}
+}
+
+// recordResult adds the result of a ranked game to the leaderboard for its mode and goal,
+// games with a goal only rank once it is reached.
+// It returns true if the result made the leaderboard
+func (g *Game) recordResult() bool {
+	g.rank = 0
+	g.result.Mode = g.options.Mode
+	g.result.LineTarget = g.options.LineTarget
+	g.result.TimeLimit = g.options.TimeLimit
+	g.result.Score = g.Player.Score
+	g.result.Level = g.Player.Level
+	g.result.Rows = g.Player.TotalRows
+	g.result.Elapsed = g.elapsed()
+	g.result.Date = g.clock.Now()
+
+	if !g.options.Ranked || (g.options.hasGoal() && !g.result.Completed) {
+		return false
+	}
+	g.rank = g.highScores.Add(g.result)
+	return g.rank > 0
+}
+
+// Result returns the result of the last game to end
+func (g *Game) Result() GameResult {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.result
+}
+
+// ResultRank returns the rank of the last game to end on its leaderboard
+// starting from 1, or 0 if it didn't place
+func (g *Game) ResultRank() int {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.rank
+}
+
+// SetResultName names the last game to end on its leaderboard
+// and saves the high scores
+func (g *Game) SetResultName(name string) error {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.rank == 0 {
+		return nil
+	}
+	leaderboard := g.highScores.find(g.result.Key())
+	if leaderboard == nil || g.rank > len(leaderboard.Results) || leaderboard.Results[g.rank-1] != g.result {
+		// the result has since been pushed off the leaderboard
+		return nil
+	}
+	g.result.Name = name
+	leaderboard.Results[g.rank-1].Name = name
+	if g.highScoresPath == "" {
+		return nil
+	}
+	return g.saveHighScores()
+}
+
+// Leaderboard returns the ranked results of a leaderboard
+func (g *Game) Leaderboard(key LeaderboardKey) Leaderboard {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.highScores.Leaderboard(key)
+}
+
+// LeaderboardKey returns the leaderboard the current game ranks on
+func (g *Game) LeaderboardKey() LeaderboardKey {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.options.LeaderboardKey()
 }
 
 func (g *Game) IsAudioPlaying() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.isAudioPlaying()
+}
+
+func (g *Game) isAudioPlaying() bool {
 	switch g.audioPlayer.State() {
 	case audio.Playing:
 		return true
@@ -122,8 +544,10 @@ func (g *Game) IsAudioPlaying() bool {
 }
 
 func (g *Game) ToggleAudio() {
+	g.mutex.Lock()
+	defer g.unlock()
 
-	if g.IsAudioPlaying() {
+	if g.isAudioPlaying() {
 		g.audioOn = false
 		g.audioPlayer.Stop()
 	} else {
@@ -133,104 +557,580 @@ func (g *Game) ToggleAudio() {
 
 }
 
-func (g *Game) run() {
+// SetClock sets the clock used to drive the game in real time
+func (g *Game) SetClock(clock Clock) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.clock = clock
+}
+
+// Tick advances the game by dt, running as many whole frames as fit.
+// Any time left over is carried into the next call
+func (g *Game) Tick(dt time.Duration) {
+	g.mutex.Lock()
+	defer g.unlock()
+
+	if g.state != Playing {
+		return
+	}
+
+	g.frameTime += dt
+	for g.frameTime >= FrameDuration && g.state == Playing {
+		g.frameTime -= FrameDuration
+		g.step()
+	}
+}
+
+// Step advances the game by a single frame
+func (g *Game) Step() {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.step()
+}
+
+func (g *Game) step() {
+	if g.state != Playing {
+		return
+	}
+
+	g.frame++
+	if g.options.TimeLimit > 0 && g.elapsed() >= g.options.TimeLimit {
+		g.complete()
+		return
+	}
 
-	for g.state == Playing {
-		// drop blocks exery x milliseconds
+	// drop blocks a row at a time as gravity builds up,
+	// fast levels drop several rows a frame
+	g.gravity += g.gravityPerFrame()
+	for g.gravity >= GravityDenominator {
+		g.gravity -= GravityDenominator
+		if !g.fall() {
+			// resting on the stack
+			g.gravity = 0
+			break
+		}
+		if g.softDrop {
+			g.softDropRows++
+		}
+	}
 
-		// calc delay speed
-		delaySpeed := BlockStartSpeed - ((g.Player.Level - 1) * LevelSpeedIncrease)
-		time.Sleep(time.Duration(delaySpeed) * time.Millisecond)
-		g.MoveDown()
+	// lock shapes that have rested on the stack for long enough
+	if g.board.canPlayerFitAt(g.Player, g.Player.X, g.Player.Y-1) {
+		g.lockFrames = 0
+		return
 	}
+	g.lockFrames++
+	if g.lockFrames >= g.options.LockDelay {
+		g.lockShape()
+	}
+}
 
+// Frame returns the number of frames played in the current game
+func (g *Game) Frame() int {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.frame
 }
 
-func (g *Game) LoadHighScores() {
-	// TODO
+// Elapsed returns the game time played in the current game
+func (g *Game) Elapsed() time.Duration {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.elapsed()
 }
 
-func (g *Game) SaveHighScores() {
-	// TODO
+func (g *Game) elapsed() time.Duration {
+	// FrameDuration is rounded down, so work from whole seconds
+	return time.Duration(g.frame) * time.Second / FramesPerSecond
 }
 
+// gravityPerFrame returns how far the shape falls each frame
+// in 1/GravityDenominator rows
+func (g *Game) gravityPerFrame() int {
+	gravity := g.options.Gravity.Gravity(g.Player.Level)
+	if g.softDrop {
+		gravity *= SoftDropSpeed
+		// soft drop falls at least a row a frame
+		if gravity < GravityDenominator {
+			gravity = GravityDenominator
+		}
+	}
+	return gravity
+}
+
+// start drives the game in real time, only one driver runs at a time
+func (g *Game) start() {
+	if g.stopRunning != nil {
+		return
+	}
+	g.stopRunning = make(chan struct{})
+	go g.run(g.clock, g.stopRunning)
+}
+
+// stop stops the real time driver
+func (g *Game) stop() {
+	if g.stopRunning == nil {
+		return
+	}
+	close(g.stopRunning)
+	g.stopRunning = nil
+}
+
+func (g *Game) run(clock Clock, stopRunning chan struct{}) {
+
+	ticker := time.NewTicker(FrameDuration)
+	defer ticker.Stop()
+
+	last := clock.Now()
+	for {
+		select {
+		case <-stopRunning:
+			return
+		case <-ticker.C:
+			now := clock.Now()
+			g.Tick(now.Sub(last))
+			last = now
+		}
+	}
+
+}
+
+// SetHighScoresPath sets the file high scores are loaded from and saved to,
+// results are saved each time a game makes the leaderboard once it is set
+func (g *Game) SetHighScoresPath(path string) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.highScoresPath = path
+}
+
+// LoadHighScores replaces the high scores with those saved on disk.
+// If the file can't be read the game starts with an empty table
+// and the error is returned
+func (g *Game) LoadHighScores() error {
+	g.mutex.Lock()
+	defer g.unlock()
+	highScores, err := ReadHighScores(g.highScoresPath)
+	g.highScores = highScores
+	return err
+}
+
+// SaveHighScores writes the high scores to disk
+func (g *Game) SaveHighScores() error {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.saveHighScores()
+}
+
+func (g *Game) saveHighScores() error {
+	return g.highScores.Write(g.highScoresPath)
+}
+
+// GetBlocks returns the board cells, they are not guarded against
+// changes by a running game so renderers should use Snapshot instead
 func (g *Game) GetBlocks() *[][]*Block {
+	g.mutex.Lock()
+	defer g.unlock()
 	return &g.board.cells
 }
 
 func (g *Game) newShape() {
 	g.Player.setNextRandomShape()
+	g.Player.canHold = true
+	g.resetLockState()
 	if !g.board.canPlayerFitAt(g.Player, g.Player.X, g.Player.Y) {
-		g.GameOver()
+		g.topOut()
+		if g.state != Playing {
+			return
+		}
 	}
+	g.raise(Event{Type: ShapeSpawnedEvent, ShapeType: g.Player.shape.Type()})
 }
 
 func (g *Game) GetState() GameState {
+	g.mutex.Lock()
+	defer g.unlock()
 	return g.state
 }
 
 func (g *Game) GetPreviousState() GameState {
+	g.mutex.Lock()
+	defer g.unlock()
 	return g.prevState
 }
 
 func (g *Game) ChangeState(newState GameState) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.changeState(newState)
+}
+
+func (g *Game) changeState(newState GameState) {
 	g.prevState = g.state
+	g.setState(newState)
+}
+
+// setState changes state without remembering the previous state
+func (g *Game) setState(newState GameState) {
+	if newState == g.state {
+		return
+	}
+	previousState := g.state
 	g.state = newState
+	g.raise(Event{Type: StateChangedEvent, State: newState, PreviousState: previousState})
 }
 
 func (g *Game) Rotate() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(RotateCommand)
+	return g.rotate()
+}
+
+func (g *Game) rotate() bool {
+	return g.turn(1)
+}
+
+// RotateCCW rotates the shape anticlockwise
+func (g *Game) RotateCCW() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(RotateCCWCommand)
+	return g.turn(-1)
+}
+
+// Rotate180 turns the shape upside down
+func (g *Game) Rotate180() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(Rotate180Command)
+	return g.turn(2)
+}
+
+// turn rotates the shape by a number of clockwise quarter turns,
+// negative turns rotate anticlockwise
+func (g *Game) turn(turns int) bool {
 	// rotate shape
-	g.Player.Rotate()
+	from := g.Player.shape.Rotation()
+	g.Player.turn(turns)
+	to := g.Player.shape.Rotation()
 
-	// test if player's block fits
-	if !g.board.canPlayerFitAt(g.Player, g.Player.X, g.Player.Y) {
+	// test if player's block fits anywhere it can be kicked to
+	kick := g.kick(from, to)
+	if kick < 0 {
 		// rotate it back
-		g.Player.RotateBack()
+		g.Player.turn(-turns)
 		return false
 	}
+	g.rotated = true
+	g.lastKick = kick
+	g.resetLockDelay()
 	return true
 }
 
-func (g *Game) MoveDown() bool {
-	// test if player's block fits
-	if g.board.canPlayerFitAt(g.Player, g.Player.X, g.Player.Y-1) {
-		g.Player.MoveDown()
-		return true
-	} else {
-		g.board.addShapeToBoard(g.Player)
-		g.newShape()
-		fullRows := g.board.checkCompleteRows()
-		if fullRows > 0 {
-			// some rows completed, update score
-			g.Player.Score += ScorePerRow
-			g.Player.TotalRows += fullRows
-			// check for level change
-			beforeLevel := g.Player.Level
-			g.Player.Level = (g.Player.TotalRows / RowsPerLevel) + 1
-
-			if beforeLevel != g.Player.Level {
-				// TODO - do something
-			}
+// kick tries each kick for a rotation in turn and moves the player
+// to the first position the rotated shape fits,
+// returning the index of the kick used or -1 if none fit
+func (g *Game) kick(from, to RotationState) int {
+	for i, kick := range kicksFor(g.Player.shape.Type(), from, to) {
+		if g.board.canPlayerFitAt(g.Player, g.Player.X+kick.X, g.Player.Y+kick.Y) {
+			g.Player.X += kick.X
+			g.Player.Y += kick.Y
+			return i
 		}
-		g.SetBoardDirty()
+	}
+	return -1
+}
+
+// Execute carries out a player command,
+// returning false if the command could not be carried out
+func (g *Game) Execute(command Command) bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(command)
+	return g.execute(command)
+}
+
+func (g *Game) execute(command Command) bool {
+	switch command {
+	case MoveLeftCommand:
+		return g.moveLeft()
+	case MoveRightCommand:
+		return g.moveRight()
+	case MoveDownCommand:
+		return g.playerMoveDown()
+	case RotateCommand:
+		return g.turn(1)
+	case RotateCCWCommand:
+		return g.turn(-1)
+	case Rotate180Command:
+		return g.turn(2)
+	case HardDropCommand:
+		g.hardDrop()
+		return true
+	case HoldCommand:
+		return g.hold()
+	case SoftDropOnCommand:
+		g.softDrop = true
+		return true
+	case SoftDropOffCommand:
+		g.softDrop = false
+		return true
+	case EndGameCommand:
+		g.gameOver()
+		return true
+	default:
+		return false
+	}
+}
+
+// Hold puts the current shape aside and brings back the shape held
+// before it, a shape can only be held once until the next shape locks
+func (g *Game) Hold() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(HoldCommand)
+	return g.hold()
+}
+
+func (g *Game) hold() bool {
+	if !g.Player.hold() {
+		return false
+	}
+	g.raise(Event{Type: ShapeHeldEvent, ShapeType: g.Player.heldShape.Type()})
+	g.resetLockState()
+	g.dirty = true
+
+	if !g.board.canPlayerFitAt(g.Player, g.Player.X, g.Player.Y) {
+		g.topOut()
+	}
+	return true
+}
+
+func (g *Game) MoveDown() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
 		return false
 	}
+	g.record(MoveDownCommand)
+	return g.playerMoveDown()
+}
+
+// playerMoveDown moves the shape down a row, a resting shape
+// is left to lock once the lock delay runs out the same as under gravity
+func (g *Game) playerMoveDown() bool {
+	if !g.fall() {
+		return false
+	}
+	// player moves score as soft drops
+	g.softDropRows++
+	return true
+}
 
+// fall moves the shape down a row if it fits,
+// returning false when it is resting on the stack
+func (g *Game) fall() bool {
+	if !g.board.canPlayerFitAt(g.Player, g.Player.X, g.Player.Y-1) {
+		return false
+	}
+	g.Player.MoveDown()
+	g.rotated = false
+	if g.Player.Y < g.lowestY {
+		// reaching a new lowest row earns more lock delay resets
+		g.lowestY = g.Player.Y
+		g.lockResets = 0
+	}
+	return true
+}
+
+// resetLockDelay restarts the lock delay of a resting shape
+// after it moves or rotates, until it runs out of resets
+func (g *Game) resetLockDelay() {
+	if g.lockFrames == 0 || g.lockResets >= g.options.LockResets {
+		return
+	}
+	g.lockFrames = 0
+	g.lockResets++
+}
+
+// resetLockState starts the lock delay afresh for a new shape
+func (g *Game) resetLockState() {
+	g.lockFrames = 0
+	g.lockResets = 0
+	g.lowestY = g.Player.Y
+	g.rotated = false
+}
+
+// HardDrop drops the shape as far as it will go and locks it straight away,
+// returning the number of rows dropped
+func (g *Game) HardDrop() int {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return 0
+	}
+	g.record(HardDropCommand)
+	return g.hardDrop()
+}
+
+func (g *Game) hardDrop() int {
+	_, ghostY := g.ghostPosition()
+	distance := g.Player.Y - ghostY
+	g.Player.Y = ghostY
+	g.hardDropRows = distance
+	if distance > 0 {
+		g.rotated = false
+	}
+	g.lockShape()
+	return distance
+}
+
+// GhostPosition returns where the current shape would land if it was dropped
+func (g *Game) GhostPosition() (int, int) {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.Player == nil {
+		return 0, 0
+	}
+	return g.ghostPosition()
+}
+
+func (g *Game) ghostPosition() (int, int) {
+	y := g.Player.Y
+	for g.board.canPlayerFitAt(g.Player, g.Player.X, y-1) {
+		y--
+	}
+	return g.Player.X, y
+}
+
+// SetSoftDrop speeds up gravity while soft drop is on,
+// it is ignored while the game isn't being played the same as other commands
+func (g *Game) SetSoftDrop(softDrop bool) {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing || softDrop == g.softDrop {
+		return
+	}
+	if softDrop {
+		g.record(SoftDropOnCommand)
+	} else {
+		g.record(SoftDropOffCommand)
+	}
+	g.softDrop = softDrop
+}
+
+// lockShape adds the shape to the board, clears any complete rows,
+// scores the lock and deals the next shape
+func (g *Game) lockShape() {
+	// corners are checked before the shape is added to the board
+	tSpin := g.tSpin()
+	g.board.addShapeToBoard(g.Player)
+	fullRows := g.board.checkCompleteRows()
+
+	lock := LockResult{
+		ShapeType:    g.Player.shape.Type(),
+		TSpin:        tSpin,
+		Rows:         fullRows,
+		Level:        g.Player.Level,
+		SoftDropRows: g.softDropRows,
+		HardDropRows: g.hardDropRows,
+	}
+	g.softDropRows = 0
+	g.hardDropRows = 0
+
+	// track consecutive clears
+	g.Player.updateStreaks(lock)
+	if g.Player.Combo > 1 {
+		lock.Combo = g.Player.Combo - 1
+	}
+	lock.BackToBack = lock.Difficult() && g.Player.BackToBack > 1
+
+	lock.Score = g.options.Scorer.Score(lock)
+	g.Player.Score += lock.Score.Total
+	g.lastLock = lock
+	g.raise(Event{Type: BlockDownEvent, Lock: lock})
+	if lock.TSpin != NoTSpin {
+		g.raise(Event{Type: TSpinEvent, ShapeType: lock.ShapeType, Lock: lock})
+	}
+
+	if len(fullRows) > 0 {
+		// some rows completed, update rows
+		g.Player.TotalRows += len(fullRows)
+		g.raise(Event{Type: RowsCompleteEvent, Rows: fullRows})
+		// check for level change
+		beforeLevel := g.Player.Level
+		g.Player.Level = (g.Player.TotalRows / RowsPerLevel) + 1
+		if maxLevel := g.options.Gravity.MaxLevel(); maxLevel > 0 && g.Player.Level > maxLevel {
+			g.Player.Level = maxLevel
+		}
+
+		if beforeLevel != g.Player.Level {
+			g.raise(Event{Type: LevelUpEvent, Level: g.Player.Level})
+		}
+
+		if g.options.LineTarget > 0 && g.Player.TotalRows >= g.options.LineTarget {
+			g.complete()
+			g.dirty = true
+			return
+		}
+	}
+	// only spawn once rows are cleared so they make room
+	g.newShape()
+	g.dirty = true
 }
 
 func (g *Game) MoveLeft() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(MoveLeftCommand)
+	return g.moveLeft()
+}
+
+func (g *Game) moveLeft() bool {
 	// test if player's block fits
 	if g.board.canPlayerFitAt(g.Player, g.Player.X-1, g.Player.Y) {
 		g.Player.MoveLeft()
+		g.rotated = false
+		g.resetLockDelay()
 		return true
 	}
 	return false
 }
 
 func (g *Game) MoveRight() bool {
+	g.mutex.Lock()
+	defer g.unlock()
+	if g.state != Playing {
+		return false
+	}
+	g.record(MoveRightCommand)
+	return g.moveRight()
+}
+
+func (g *Game) moveRight() bool {
 	// test if player's block fits
 	if g.board.canPlayerFitAt(g.Player, g.Player.X+1, g.Player.Y) {
 		g.Player.MoveRight()
+		g.rotated = false
+		g.resetLockDelay()
 		return true
 	}
 	return false
@@ -1,6 +1,28 @@
 package domain
 
-import "testing"
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestGame returns a game in play with the given options and seed,
+// without audio or a real time driver
+func newTestGame(options GameOptions, seed int64) *Game {
+	game := NewGameWithOptions(options)
+	game.SetSeed(seed)
+	game.mutex.Lock()
+	game.newGame()
+	game.unlock()
+	return game
+}
+
+// framesPerRow returns how many frames a shape takes to fall one row
+func framesPerRow(game *Game) int {
+	gravity := game.gravityPerFrame()
+	return (GravityDenominator + gravity - 1) / gravity
+}
 
 func TestNewGame(t *testing.T) {
 
@@ -22,3 +44,425 @@ func TestNewGame(t *testing.T) {
 	}
 
 }
+
+func TestBoardSizeOption(t *testing.T) {
+
+	options := DefaultGameOptions()
+	options.BoardSize = NarrowBoard
+	game := newTestGame(options, 15)
+
+	if game.BoardSize() != NarrowBoard {
+		t.Errorf("Expected board size: %v received: %v", NarrowBoard, game.BoardSize())
+	}
+
+	// shapes spawn at the top middle of the narrow board
+	if game.Player.X != NarrowBoard.Width/2 || game.Player.Y != NarrowBoard.Height-3 {
+		t.Errorf("Expected spawn at: %d,%d received: %d,%d", NarrowBoard.Width/2, NarrowBoard.Height-3, game.Player.X, game.Player.Y)
+	}
+
+	// shapes stop at the narrow board's right hand wall
+	for game.MoveRight() {
+	}
+	for _, block := range game.Player.GetShapeBlocks() {
+		if game.Player.X+block.X > NarrowBoard.Width-2 {
+			t.Errorf("Expected shape inside board received x: %d", game.Player.X+block.X)
+		}
+	}
+
+	game.SetBoardSize(TallBoard)
+	game.mutex.Lock()
+	game.newGame()
+	game.unlock()
+	if game.BoardSize() != TallBoard {
+		t.Errorf("Expected board size: %v received: %v", TallBoard, game.BoardSize())
+	}
+}
+
+func TestSetModeKeepsOptions(t *testing.T) {
+
+	options := DefaultGameOptions()
+	options.Randomizer = UniformRandomizer
+	options.Previews = 5
+	game := NewGameWithOptions(options)
+	game.SetMode(SprintMode)
+	if game.options.Randomizer != UniformRandomizer || game.options.Previews != 5 {
+		t.Errorf("Expected randomizer: %d previews: %d received randomizer: %d previews: %d",
+			UniformRandomizer, 5, game.options.Randomizer, game.options.Previews)
+	}
+
+	game.SetMode(UltraMode)
+	game.SetTimeLimit(ShortUltraDuration)
+	game.SetMode(UltraMode)
+	if key := game.LeaderboardKey(); key.TimeLimit != ShortUltraDuration {
+		t.Errorf("Expected time limit: %s received: %s", ShortUltraDuration, key.TimeLimit)
+	}
+
+	// untimed modes play on until the game is lost
+	game.SetMode(MarathonMode)
+	if key := game.LeaderboardKey(); key.TimeLimit != 0 {
+		t.Errorf("Expected no time limit received: %s", key.TimeLimit)
+	}
+}
+
+func TestSeededGamesDealSameShapes(t *testing.T) {
+
+	first := newTestGame(DefaultGameOptions(), 99)
+
+	second := newTestGame(DefaultGameOptions(), 99)
+
+	if first.Seed() != 99 {
+		t.Errorf("Expected seed: %d received: %d", 99, first.Seed())
+	}
+
+	for i := 0; i < 50; i++ {
+		if !reflect.DeepEqual(first.Player.GetShapeBlocks(), second.Player.GetShapeBlocks()) {
+			t.Fatalf("Shape %d differs", i)
+		}
+		if !reflect.DeepEqual(first.Player.GetNextShapeBlocks(), second.Player.GetNextShapeBlocks()) {
+			t.Fatalf("Next shape %d differs", i)
+		}
+		first.Player.setNextRandomShape()
+		second.Player.setNextRandomShape()
+	}
+}
+
+func TestTickAppliesGravity(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 1)
+
+	startY := game.Player.Y
+	rowFrames := framesPerRow(game)
+
+	// just short of a row
+	game.Tick(time.Duration(rowFrames-1) * FrameDuration)
+	if game.Player.Y != startY {
+		t.Errorf("Expected Y: %d received: %d", startY, game.Player.Y)
+	}
+
+	// remainder of a row split across ticks
+	game.Tick(FrameDuration / 2)
+	game.Tick(FrameDuration / 2)
+	if game.Player.Y != startY-1 {
+		t.Errorf("Expected Y: %d received: %d", startY-1, game.Player.Y)
+	}
+
+	if game.Frame() != rowFrames {
+		t.Errorf("Expected frame: %d received: %d", rowFrames, game.Frame())
+	}
+}
+
+func TestTickFastForwardIsDeterministic(t *testing.T) {
+
+	first := newTestGame(DefaultGameOptions(), 5)
+	first.Tick(10 * time.Minute)
+
+	second := newTestGame(DefaultGameOptions(), 5)
+	for i := 0; i < 600; i++ {
+		second.Tick(time.Second)
+	}
+
+	if first.GetState() != GameOver {
+		t.Errorf("Expected state: %d received: %d", GameOver, first.GetState())
+	}
+	if !reflect.DeepEqual(first.board, second.board) {
+		t.Error("Boards differ")
+	}
+	if first.Frame() != second.Frame() {
+		t.Errorf("Frames differ %d and %d", first.Frame(), second.Frame())
+	}
+}
+
+func TestConcurrentInputAndGravity(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 11)
+
+	commands := []func() bool{game.MoveLeft, game.MoveRight, game.Rotate, game.MoveDown}
+
+	var wg sync.WaitGroup
+	for _, command := range commands {
+		wg.Add(1)
+		go func(command func() bool) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				command()
+			}
+		}(command)
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			game.Tick(FrameDuration * 5)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			snapshot := game.Snapshot()
+			if len(snapshot.Board) != BoardWidth {
+				t.Errorf("Expected width: %d received: %d", BoardWidth, len(snapshot.Board))
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+}
+
+func TestHold(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 8)
+	player := game.Player
+
+	first := player.shape
+	next := player.queue[0]
+	first.Rotate()
+	game.MoveLeft()
+
+	if !game.Hold() {
+		t.Fatal("Expected first hold to succeed")
+	}
+	if player.heldShape != first || player.shape != next {
+		t.Error("Expected current shape held and next shape dealt")
+	}
+	if first.viewIndex != 0 {
+		t.Errorf("Expected held shape rotation reset received: %d", first.viewIndex)
+	}
+	if player.X != BoardWidth/2 || player.Y != BoardHeight-3 {
+		t.Errorf("Expected spawn position received: %d,%d", player.X, player.Y)
+	}
+
+	if game.Hold() {
+		t.Error("Expected second hold of same shape to fail")
+	}
+
+	// lock the shape to allow holding again
+	game.HardDrop()
+	second := player.shape
+	if !game.Hold() {
+		t.Fatal("Expected hold after lock to succeed")
+	}
+	if player.shape != first || player.heldShape != second {
+		t.Error("Expected held shape to swap back in")
+	}
+}
+
+func TestHardDrop(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 4)
+
+	// measure how far the shape can fall
+	expected := 0
+	for game.board.canPlayerFitAt(game.Player, game.Player.X, game.Player.Y-expected-1) {
+		expected++
+	}
+	next := game.Player.queue[0]
+
+	distance := game.HardDrop()
+	if distance != expected {
+		t.Errorf("Expected distance: %d received: %d", expected, distance)
+	}
+	if game.Player.shape != next {
+		t.Error("Expected shape to lock and next shape to be dealt")
+	}
+	if game.Player.Score != distance*HardDropScorePerRow {
+		t.Errorf("Expected score: %d received: %d", distance*HardDropScorePerRow, game.Player.Score)
+	}
+}
+
+func TestSoftDrop(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 4)
+	startY := game.Player.Y
+	rowFrames := framesPerRow(game)
+
+	game.SetSoftDrop(true)
+	softFrames := framesPerRow(game)
+	if softFrames >= rowFrames {
+		t.Errorf("Expected soft drop faster than %d frames per row received: %d", rowFrames, softFrames)
+	}
+
+	game.Tick(time.Duration(10*softFrames) * FrameDuration)
+	game.SetSoftDrop(false)
+
+	rows := startY - game.Player.Y
+	if rows != 10 {
+		t.Errorf("Expected rows: %d received: %d", 10, rows)
+	}
+
+	// drop points are awarded when the shape locks
+	distance := game.HardDrop()
+	expected := rows*SoftDropScorePerRow + distance*HardDropScorePerRow
+	if game.Player.Score != expected {
+		t.Errorf("Expected score: %d received: %d", expected, game.Player.Score)
+	}
+
+	// drop points of a game that ends mid drop are not carried into the next game
+	game.SetSoftDrop(true)
+	game.Tick(time.Duration(2*softFrames) * FrameDuration)
+	game.mutex.Lock()
+	game.newGame()
+	game.unlock()
+	game.HardDrop()
+	if lock := game.lastLock; lock.SoftDropRows != 0 {
+		t.Errorf("Expected no soft drop rows from the last game received: %d", lock.SoftDropRows)
+	}
+}
+
+func TestGhostPosition(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 6)
+
+	// raise the floor under the spawn position
+	fillRow(&game.board, 1)
+	fillRow(&game.board, 2)
+
+	x, y := game.GhostPosition()
+	if x != game.Player.X {
+		t.Errorf("Expected ghost X: %d received: %d", game.Player.X, x)
+	}
+	if !game.board.canPlayerFitAt(game.Player, x, y) || game.board.canPlayerFitAt(game.Player, x, y-1) {
+		t.Errorf("Expected ghost to rest on the stack at Y: %d", y)
+	}
+
+	startY := game.Player.Y
+	if distance := game.HardDrop(); distance != startY-y {
+		t.Errorf("Expected hard drop distance: %d received: %d", startY-y, distance)
+	}
+}
+
+func TestLockDelay(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 9)
+	game.options.LockDelay = 10
+	game.options.LockResets = 2
+
+	// rest shape on the floor
+	_, game.Player.Y = game.GhostPosition()
+	shape := game.Player.shape
+
+	game.Tick(9 * FrameDuration)
+	if game.Player.shape != shape {
+		t.Fatal("Expected shape not to lock before lock delay")
+	}
+
+	// moving restarts the lock delay until resets run out
+	for _, move := range []func() bool{game.MoveLeft, game.MoveRight} {
+		if !move() {
+			t.Fatal("Expected shape to move")
+		}
+		game.Tick(9 * FrameDuration)
+		if game.Player.shape != shape {
+			t.Fatal("Expected move to restart lock delay")
+		}
+	}
+
+	game.MoveLeft()
+	game.Tick(FrameDuration)
+	if game.Player.shape == shape {
+		t.Error("Expected shape to lock once resets ran out")
+	}
+}
+
+func TestMoveDownWaitsForLockDelay(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 9)
+	game.options.LockDelay = 10
+
+	// rest shape on the floor
+	_, game.Player.Y = game.GhostPosition()
+	shape := game.Player.shape
+
+	// moving a resting shape down doesn't lock it or restart the lock delay
+	game.Tick(5 * FrameDuration)
+	if game.MoveDown() || game.Player.shape != shape {
+		t.Fatal("Expected resting shape not to move or lock")
+	}
+	game.Tick(4 * FrameDuration)
+	if game.Player.shape != shape {
+		t.Fatal("Expected shape not to lock before lock delay")
+	}
+	game.Tick(FrameDuration)
+	if game.Player.shape == shape {
+		t.Error("Expected shape to lock once the lock delay ran out")
+	}
+}
+
+func TestZenModeClearsStackOnTopOut(t *testing.T) {
+
+	game := newTestGame(ModeOptions(ZenMode), 17)
+
+	if game.Ranked() {
+		t.Error("Expected zen mode to be unranked")
+	}
+
+	cleared := 0
+	game.Subscribe(EventListenerFunc(func(event Event) {
+		if event.Type == StackClearedEvent {
+			cleared++
+		}
+	}))
+
+	// stack up to just below the spawn position
+	for y := 1; y < game.Player.Y; y++ {
+		fillRow(&game.board, y, y%10+1)
+	}
+	game.HardDrop()
+	for i := 0; i < 10 && cleared == 0; i++ {
+		game.HardDrop()
+	}
+
+	if game.GetState() != Playing {
+		t.Errorf("Expected state: %d received: %d", Playing, game.GetState())
+	}
+	if cleared != 1 {
+		t.Errorf("Expected stack cleared events: %d received: %d", 1, cleared)
+	}
+	if game.board.isFilled(1, 1) {
+		t.Error("Expected stack to be cleared")
+	}
+
+	game.EndGame()
+	if game.GetState() != GameOver {
+		t.Errorf("Expected state: %d received: %d", GameOver, game.GetState())
+	}
+}
+
+func TestNextQueue(t *testing.T) {
+
+	// shapes are dealt in the same order whatever the length of the queue
+	dealt := func(previews int) []ShapeType {
+		options := DefaultGameOptions()
+		options.Previews = previews
+		game := newTestGame(options, 25)
+
+		expected := previews
+		if previews < 1 {
+			expected = 1
+		}
+		if previews > MaxPreviews {
+			expected = MaxPreviews
+		}
+		if len(game.Player.GetQueueBlocks()) != expected {
+			t.Errorf("Expected queue of %d shapes received: %d", expected, len(game.Player.GetQueueBlocks()))
+		}
+		if !reflect.DeepEqual(game.Player.GetQueueBlocks()[0], game.Player.GetNextShapeBlocks()) {
+			t.Error("Expected the next shape at the front of the queue")
+		}
+
+		types := []ShapeType{game.Player.shape.shapeType}
+		for i := 0; i < 10; i++ {
+			game.HardDrop()
+			types = append(types, game.Player.shape.shapeType)
+		}
+		return types
+	}
+
+	expected := dealt(1)
+	for _, previews := range []int{0, 3, MaxPreviews, MaxPreviews + 1} {
+		if types := dealt(previews); !reflect.DeepEqual(types, expected) {
+			t.Errorf("Expected queue of %d to deal: %v received: %v", previews, expected, types)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+// GravityCurve is the speed shapes fall at each level starting from level 1,
+// in 1/GravityDenominator rows per frame. The last level in the curve is
+// the maximum level a game can reach
+type GravityCurve []int
+
+// Gravity returns the rows per frame for a level,
+// in 1/GravityDenominator rows per frame
+func (c GravityCurve) Gravity(level int) int {
+	if len(c) == 0 {
+		return GravityDenominator
+	}
+	if level < 1 {
+		level = 1
+	}
+	if level > len(c) {
+		level = len(c)
+	}
+	return c[level-1]
+}
+
+// MaxLevel returns the highest level in the curve
+func (c GravityCurve) MaxLevel() int {
+	return len(c)
+}
+
+// flatGravity returns a curve that keeps the same speed through the given number of levels
+func flatGravity(gravity, levels int) GravityCurve {
+	curve := make(GravityCurve, levels)
+	for i := range curve {
+		curve[i] = gravity
+	}
+	return curve
+}
+
+// GuidelineGravity speeds up each level until shapes drop
+// straight to the bottom at 20G on the last level
+var GuidelineGravity = GravityCurve{
+	4, 5, 7, 9, 12, // about 1 row a second at level 1
+	16, 22, 32, 45, 67,
+	99, 152, 237, 388, 610,
+	1024, 2048, 3072, 4096, 20 * GravityDenominator,
+}
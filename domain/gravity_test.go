@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// original Teletris speed in milliseconds a row
+const (
+	classicStartSpeed    = 500
+	classicSpeedIncrease = 50 // per level
+)
+
+// classicGravity is the original Teletris speed, starting at classicStartSpeed
+// milliseconds a row and falling by classicSpeedIncrease every level
+func classicGravity() GravityCurve {
+	curve := GravityCurve{}
+	for delay := classicStartSpeed; delay > 0; delay -= classicSpeedIncrease {
+		frames := int(time.Duration(delay) * time.Millisecond / FrameDuration)
+		curve = append(curve, GravityDenominator/frames)
+	}
+	return curve
+}
+
+func TestGravityCurves(t *testing.T) {
+
+	for name, curve := range map[string]GravityCurve{"guideline": GuidelineGravity, "classic": classicGravity()} {
+		last := 0
+		for level := 1; level <= curve.MaxLevel()+5; level++ {
+			gravity := curve.Gravity(level)
+			if gravity <= 0 {
+				t.Errorf("%s Expected positive gravity at level %d received: %d", name, level, gravity)
+			}
+			if gravity < last {
+				t.Errorf("%s Expected gravity to increase at level %d received: %d", name, level, gravity)
+			}
+			last = gravity
+		}
+	}
+
+	if GuidelineGravity.Gravity(GuidelineGravity.MaxLevel()) != 20*GravityDenominator {
+		t.Errorf("Expected 20G at max level received: %d", GuidelineGravity.Gravity(GuidelineGravity.MaxLevel()))
+	}
+}
+
+func TestTwentyGDropsInOneFrame(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 16)
+	game.Player.Level = GuidelineGravity.MaxLevel()
+
+	_, ghostY := game.GhostPosition()
+	game.Step()
+	if game.Player.Y != ghostY {
+		t.Errorf("Expected Y: %d received: %d", ghostY, game.Player.Y)
+	}
+}
+
+func TestLevelStopsAtMaxLevel(t *testing.T) {
+
+	options := DefaultGameOptions()
+	options.Gravity = classicGravity()
+	game := newTestGame(options, 16)
+
+	game.Player.TotalRows = 100 * RowsPerLevel
+	fillRow(&game.board, 1)
+	game.HardDrop()
+
+	if game.Player.Level != options.Gravity.MaxLevel() {
+		t.Errorf("Expected level: %d received: %d", options.Gravity.MaxLevel(), game.Player.Level)
+	}
+}
+
+func TestZenLevelsUpAtFixedSpeed(t *testing.T) {
+
+	game := newTestGame(ModeOptions(ZenMode), 17)
+	game.Player.TotalRows = RowsPerLevel - 1
+	fillRow(&game.board, 1, 1, 2, 3, 4)
+	game.Player.shape = BarShape(Blue)
+	game.Player.X = 1
+	game.Player.Y = 0
+	game.HardDrop()
+
+	if game.Player.Level != 2 {
+		t.Errorf("Expected level: %d received: %d", 2, game.Player.Level)
+	}
+	if gravity := game.gravityPerFrame(); gravity != ZenGravity {
+		t.Errorf("Expected gravity: %d received: %d", ZenGravity, gravity)
+	}
+	if maxLevel := game.options.Gravity.MaxLevel(); maxLevel != GuidelineGravity.MaxLevel() {
+		t.Errorf("Expected max level: %d received: %d", GuidelineGravity.MaxLevel(), maxLevel)
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// HighScores holds the leaderboard of each game mode and goal as it is stored on disk
+type HighScores struct {
+	Version      int            `json:"version"`
+	Leaderboards []*Leaderboard `json:"leaderboards"`
+}
+
+// NewHighScores returns an empty high score table
+func NewHighScores() HighScores {
+	return HighScores{Version: HighScoresVersion}
+}
+
+// Add records a result on the leaderboard of its mode and goal,
+// returning its rank starting from 1, or 0 if it didn't place
+func (h *HighScores) Add(result GameResult) int {
+	leaderboard := h.find(result.Key())
+	if leaderboard == nil {
+		leaderboard = &Leaderboard{Key: result.Key()}
+		h.Leaderboards = append(h.Leaderboards, leaderboard)
+	}
+	return leaderboard.Add(result)
+}
+
+// Leaderboard returns a copy of a leaderboard
+func (h *HighScores) Leaderboard(key LeaderboardKey) Leaderboard {
+	leaderboard := h.find(key)
+	if leaderboard == nil {
+		return Leaderboard{Key: key}
+	}
+	return leaderboard.copy()
+}
+
+// find returns a leaderboard, or nil if nothing has ranked on it
+func (h *HighScores) find(key LeaderboardKey) *Leaderboard {
+	for _, leaderboard := range h.Leaderboards {
+		if leaderboard.Key == key {
+			return leaderboard
+		}
+	}
+	return nil
+}
+
+// ReadHighScores reads a high score table from a file.
+// A missing file is a new empty table, a corrupt file or one written by
+// another version returns an empty table along with the error
+func ReadHighScores(path string) (HighScores, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewHighScores(), nil
+	}
+	if err != nil {
+		return NewHighScores(), err
+	}
+
+	var stored HighScores
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return NewHighScores(), fmt.Errorf("corrupt high scores %s: %s", path, err)
+	}
+	if stored.Version != HighScoresVersion {
+		return NewHighScores(), fmt.Errorf("unsupported high scores version %d in %s", stored.Version, path)
+	}
+
+	// re-rank the stored results so a hand edited file can't break the ordering or size
+	highScores := NewHighScores()
+	for _, leaderboard := range stored.Leaderboards {
+		if leaderboard == nil {
+			continue
+		}
+		for _, result := range leaderboard.Results {
+			result.Mode = leaderboard.Key.Mode
+			result.LineTarget = leaderboard.Key.LineTarget
+			result.TimeLimit = leaderboard.Key.TimeLimit
+			highScores.Add(result)
+		}
+	}
+	return highScores, nil
+}
+
+// Write saves the high score table to a file
+func (h HighScores) Write(path string) error {
+	h.Version = HighScoresVersion
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func tempHighScoresPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), HighScoresFile)
+}
+
+func TestHighScoresRoundTrip(t *testing.T) {
+
+	path := tempHighScoresPath(t)
+	date := time.Date(2016, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	highScores := NewHighScores()
+	highScores.Add(GameResult{Mode: MarathonMode, Score: 1200, Level: 3, Rows: 25, Elapsed: 5 * time.Minute, Date: date})
+	highScores.Add(GameResult{Mode: SprintMode, LineTarget: SprintLines, Completed: true, Score: 800, Level: 4, Rows: 40, Elapsed: 90 * time.Second, Date: date})
+	highScores.Add(GameResult{Mode: SprintMode, LineTarget: 2, Completed: true, Score: 200, Level: 1, Rows: 2, Elapsed: 5 * time.Second, Date: date})
+
+	if err := highScores.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadHighScores(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []LeaderboardKey{
+		{Mode: MarathonMode},
+		{Mode: SprintMode, LineTarget: SprintLines},
+		{Mode: SprintMode, LineTarget: 2},
+	}
+	for _, key := range keys {
+		expected := highScores.Leaderboard(key).Results
+		received := loaded.Leaderboard(key).Results
+		if len(received) != 1 || !received[0].Date.Equal(date) {
+			t.Fatalf("Expected results: %+v received: %+v", expected, received)
+		}
+		received[0].Date = expected[0].Date
+		if received[0] != expected[0] {
+			t.Errorf("Expected result: %+v received: %+v", expected[0], received[0])
+		}
+	}
+
+	// only the high score file is left behind
+	files, _ := ioutil.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("Expected files: %d received: %d", 1, len(files))
+	}
+}
+
+func TestReadHighScoresFallsBack(t *testing.T) {
+
+	path := tempHighScoresPath(t)
+
+	highScores, err := ReadHighScores(path)
+	if err != nil {
+		t.Errorf("Expected a missing file to be an empty table received: %s", err)
+	}
+
+	contents := []string{
+		`{"version": 1, "leaderboards": {`,
+		`{"version": 99, "leaderboards": {}}`,
+	}
+	for _, content := range contents {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		highScores, err = ReadHighScores(path)
+		if err == nil {
+			t.Errorf("Expected an error reading: %s", content)
+		}
+		if highScores.Version != HighScoresVersion || len(highScores.Leaderboards) != 0 {
+			t.Errorf("Expected an empty table reading: %s received: %+v", content, highScores)
+		}
+	}
+}
+
+func TestReadHighScoresReranksResults(t *testing.T) {
+
+	path := tempHighScoresPath(t)
+
+	highScores := NewHighScores()
+	key := LeaderboardKey{Mode: MarathonMode}
+	leaderboard := &Leaderboard{Key: key}
+	for i := 0; i < MaxLeaderboardEntries+2; i++ {
+		leaderboard.Results = append(leaderboard.Results, GameResult{Mode: MarathonMode, Score: i * 100})
+	}
+	highScores.Leaderboards = append(highScores.Leaderboards, leaderboard)
+	if err := highScores.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadHighScores(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := loaded.Leaderboard(key).Results
+	if len(results) != MaxLeaderboardEntries || results[0].Score != (MaxLeaderboardEntries+1)*100 {
+		t.Errorf("Expected %d results best first received: %+v", MaxLeaderboardEntries, results)
+	}
+}
+
+func TestGameSavesHighScores(t *testing.T) {
+
+	path := tempHighScoresPath(t)
+
+	game := newTestGame(DefaultGameOptions(), 20)
+	game.SetHighScoresPath(path)
+	game.Player.Score = 500
+	game.EndGame()
+
+	if rank := game.ResultRank(); rank != 1 {
+		t.Fatalf("Expected rank: %d received: %d", 1, rank)
+	}
+	if err := game.SetResultName("ABC"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewGame()
+	restarted.SetHighScoresPath(path)
+	if err := restarted.LoadHighScores(); err != nil {
+		t.Fatal(err)
+	}
+	results := restarted.Leaderboard(LeaderboardKey{Mode: MarathonMode}).Results
+	if len(results) != 1 || results[0].Score != 500 || results[0].Name != "ABC" {
+		t.Errorf("Expected saved score: %d by: %s received: %+v", 500, "ABC", results)
+	}
+}
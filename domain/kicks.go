@@ -0,0 +1,71 @@
+package domain
+
+// Kick is an offset to try moving a rotated shape by when it doesn't fit
+type Kick struct {
+	X, Y int
+}
+
+// Rotation is a change of rotation state
+type Rotation struct {
+	From, To RotationState
+}
+
+// KickTable lists the kicks to try in order for each rotation
+type KickTable map[Rotation][]Kick
+
+// standardKicks are the SRS kicks shared by all shapes except the bar,
+// SRS has no half turns so those kicks come from later guideline games
+var standardKicks = KickTable{
+	{Rotation0, RotationR}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{RotationR, Rotation0}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{RotationR, Rotation2}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{Rotation2, RotationR}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{Rotation2, RotationL}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{RotationL, Rotation2}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{RotationL, Rotation0}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{Rotation0, RotationL}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	// half turns
+	{Rotation0, Rotation2}: {{0, 0}, {0, 1}, {1, 1}, {-1, 1}, {1, 0}, {-1, 0}},
+	{Rotation2, Rotation0}: {{0, 0}, {0, -1}, {-1, -1}, {1, -1}, {-1, 0}, {1, 0}},
+	{RotationR, RotationL}: {{0, 0}, {1, 0}, {1, 2}, {1, 1}, {0, 2}, {0, 1}},
+	{RotationL, RotationR}: {{0, 0}, {-1, 0}, {-1, 2}, {-1, 1}, {0, 2}, {0, 1}},
+}
+
+// barKicks are the SRS kicks for the bar
+var barKicks = KickTable{
+	{Rotation0, RotationR}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{RotationR, Rotation0}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{RotationR, Rotation2}: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	{Rotation2, RotationR}: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{Rotation2, RotationL}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{RotationL, Rotation2}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{RotationL, Rotation0}: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{Rotation0, RotationL}: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	// half turns
+	{Rotation0, Rotation2}: {{0, 0}, {0, 1}},
+	{Rotation2, Rotation0}: {{0, 0}, {0, -1}},
+	{RotationR, RotationL}: {{0, 0}, {1, 0}},
+	{RotationL, RotationR}: {{0, 0}, {-1, 0}},
+}
+
+// KickTables holds the kick table for each shape type
+var KickTables = map[ShapeType]KickTable{
+	Square:    KickTable{},
+	Bar:       barKicks,
+	LeftL:     standardKicks,
+	RightL:    standardKicks,
+	LeftStep:  standardKicks,
+	RightStep: standardKicks,
+	T:         standardKicks,
+}
+
+// noKicks only tries the rotated shape where it is
+var noKicks = []Kick{{0, 0}}
+
+// kicksFor returns the kicks to try for a shape rotating between two states
+func kicksFor(shapeType ShapeType, from, to RotationState) []Kick {
+	if kicks, ok := KickTables[shapeType][Rotation{From: from, To: to}]; ok {
+		return kicks
+	}
+	return noKicks
+}
@@ -0,0 +1,96 @@
+package domain
+
+import "testing"
+
+func TestKickTablesCoverEveryRotation(t *testing.T) {
+
+	for shapeType := ShapeType(Square); shapeType <= T; shapeType++ {
+		shape := NewShape(shapeType, Red)
+		if len(shape.rotations) != len(shape.views) {
+			t.Fatalf("Shape %d Expected %d rotation states received: %d", shapeType, len(shape.views), len(shape.rotations))
+		}
+		if len(shape.views) == 1 {
+			continue
+		}
+
+		// every quarter turn in both directions and every half turn
+		for _, turns := range []int{1, -1, 2} {
+			player := &Player{shape: shape}
+			for i := 0; i < len(shape.views); i++ {
+				from := shape.Rotation()
+				player.turn(turns)
+				to := shape.Rotation()
+				if from == to {
+					// half turn of a two view shape
+					continue
+				}
+				if _, ok := KickTables[shapeType][Rotation{From: from, To: to}]; !ok {
+					t.Errorf("Shape %d missing kicks from %d to %d", shapeType, from, to)
+				}
+				player.turn(-turns)
+				shape.Rotate()
+			}
+		}
+	}
+}
+
+func TestRotateKicksOffWall(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 2)
+
+	// vertical bar against the left wall
+	game.Player.shape = BarShape(Red)
+	game.Player.shape.Rotate()
+	game.Player.X = 0
+	game.Player.Y = 5
+
+	if !game.Rotate() {
+		t.Fatal("Expected bar to kick away from wall")
+	}
+	if game.Player.shape.Rotation() != Rotation2 {
+		t.Errorf("Expected rotation: %d received: %d", Rotation2, game.Player.shape.Rotation())
+	}
+	if !game.board.canPlayerFitAt(game.Player, game.Player.X, game.Player.Y) {
+		t.Error("Expected kicked shape to fit")
+	}
+	if game.Player.X != 1 {
+		t.Errorf("Expected X: %d received: %d", 1, game.Player.X)
+	}
+}
+
+func TestRotateFailsWhenNoKickFits(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 2)
+
+	// vertical bar in a one wide well
+	for y := 1; y < BoardHeight-1; y++ {
+		fillRow(&game.board, y, 5)
+	}
+	game.Player.shape = BarShape(Red)
+	game.Player.shape.Rotate()
+	game.Player.X = 4
+	game.Player.Y = 4
+
+	if game.Rotate() {
+		t.Fatal("Expected rotation to fail")
+	}
+	if game.Player.shape.Rotation() != RotationL || game.Player.X != 4 || game.Player.Y != 4 {
+		t.Error("Expected shape to be left unchanged")
+	}
+}
+
+func TestRotateDirections(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 2)
+	game.Player.shape = TShape(Red)
+
+	if !game.RotateCCW() || game.Player.shape.Rotation() != RotationR {
+		t.Errorf("Expected anticlockwise rotation to: %d received: %d", RotationR, game.Player.shape.Rotation())
+	}
+	if !game.Rotate180() || game.Player.shape.Rotation() != RotationL {
+		t.Errorf("Expected half turn to: %d received: %d", RotationL, game.Player.shape.Rotation())
+	}
+	if !game.Execute(RotateCommand) || game.Player.shape.Rotation() != Rotation0 {
+		t.Errorf("Expected clockwise rotation to: %d received: %d", Rotation0, game.Player.shape.Rotation())
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// LeaderboardKey picks out a leaderboard, games of a mode
+// played to different goals rank on separate leaderboards
+type LeaderboardKey struct {
+	Mode       GameMode      `json:"mode"`
+	LineTarget int           `json:"lineTarget,omitempty"`
+	TimeLimit  time.Duration `json:"timeLimit,omitempty"`
+}
+
+// GameResult is the outcome of a game once it has ended
+type GameResult struct {
+	Mode GameMode `json:"mode"`
+	// rows the game was played to, 0 for games without a line target
+	LineTarget int `json:"lineTarget,omitempty"`
+	// game time the game was played for, 0 for games without a time limit
+	TimeLimit time.Duration `json:"timeLimit,omitempty"`
+	// the game reached its goal, such as the sprint line target
+	Completed bool          `json:"completed"`
+	Score     int           `json:"score"`
+	Level     int           `json:"level"`
+	Rows      int           `json:"rows"`
+	Elapsed   time.Duration `json:"elapsed"`
+	// when the game ended
+	Date time.Time `json:"date"`
+	// initials entered by the player
+	Name string `json:"name"`
+}
+
+// Key returns the leaderboard the result ranks on
+func (r GameResult) Key() LeaderboardKey {
+	return LeaderboardKey{Mode: r.Mode, LineTarget: r.LineTarget, TimeLimit: r.TimeLimit}
+}
+
+// Better returns true if a result ranks above another result of the same mode,
+// timed modes rank the fastest time first and other modes the highest score
+func (r GameResult) Better(other GameResult) bool {
+	if r.Mode == SprintMode {
+		return r.Elapsed < other.Elapsed
+	}
+	return r.Score > other.Score
+}
+
+// Leaderboard ranks the results of games of a single mode and goal, best first
+type Leaderboard struct {
+	Key     LeaderboardKey `json:"key"`
+	Results []GameResult   `json:"results"`
+}
+
+// Add records a result, returning its rank starting from 1,
+// or 0 if it didn't make the leaderboard
+func (l *Leaderboard) Add(result GameResult) int {
+	rank := sort.Search(len(l.Results), func(i int) bool {
+		return result.Better(l.Results[i])
+	})
+	if rank >= MaxLeaderboardEntries {
+		return 0
+	}
+	l.Results = append(l.Results, GameResult{})
+	copy(l.Results[rank+1:], l.Results[rank:])
+	l.Results[rank] = result
+	if len(l.Results) > MaxLeaderboardEntries {
+		l.Results = l.Results[:MaxLeaderboardEntries]
+	}
+	return rank + 1
+}
+
+func (l *Leaderboard) copy() Leaderboard {
+	return Leaderboard{Key: l.Key, Results: append([]GameResult(nil), l.Results...)}
+}
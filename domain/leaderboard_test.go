@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaderboardRanksByScore(t *testing.T) {
+
+	leaderboard := Leaderboard{Key: LeaderboardKey{Mode: MarathonMode}}
+
+	for i := 1; i <= MaxLeaderboardEntries; i++ {
+		if rank := leaderboard.Add(GameResult{Mode: MarathonMode, Score: i * 100}); rank != 1 {
+			t.Errorf("Expected rank: %d received: %d", 1, rank)
+		}
+	}
+
+	if rank := leaderboard.Add(GameResult{Mode: MarathonMode, Score: 50}); rank != 0 {
+		t.Errorf("Expected rank: %d received: %d", 0, rank)
+	}
+	if rank := leaderboard.Add(GameResult{Mode: MarathonMode, Score: 550}); rank != 6 {
+		t.Errorf("Expected rank: %d received: %d", 6, rank)
+	}
+
+	if len(leaderboard.Results) != MaxLeaderboardEntries {
+		t.Errorf("Expected entries: %d received: %d", MaxLeaderboardEntries, len(leaderboard.Results))
+	}
+	if leaderboard.Results[0].Score != 1000 || leaderboard.Results[MaxLeaderboardEntries-1].Score != 200 {
+		t.Errorf("Expected scores from 1000 to 200 received: %+v", leaderboard.Results)
+	}
+}
+
+func TestLeaderboardRanksSprintsByTime(t *testing.T) {
+
+	leaderboard := Leaderboard{Key: LeaderboardKey{Mode: SprintMode}}
+	leaderboard.Add(GameResult{Mode: SprintMode, Elapsed: 90 * time.Second, Score: 1000})
+	rank := leaderboard.Add(GameResult{Mode: SprintMode, Elapsed: 60 * time.Second, Score: 10})
+
+	if rank != 1 {
+		t.Errorf("Expected fastest sprint to rank: %d received: %d", 1, rank)
+	}
+}
+
+func TestSprintCompletesAtLineTarget(t *testing.T) {
+
+	options := ModeOptions(SprintMode)
+	options.LineTarget = 2
+	game := newTestGame(options, 18)
+
+	completed := 0
+	game.Subscribe(EventListenerFunc(func(event Event) {
+		if event.Type == GameCompleteEvent {
+			completed++
+		}
+	}))
+
+	game.Tick(time.Second)
+	fillRow(&game.board, 1, 1)
+	fillRow(&game.board, 2, 1)
+	game.Player.shape = BarShape(Blue)
+	game.Player.shape.Rotate()
+	game.Player.X = 0
+	game.Player.Y = 1
+	game.HardDrop()
+
+	if game.GetState() != Finished {
+		t.Errorf("Expected state: %d received: %d", Finished, game.GetState())
+	}
+	if completed != 1 {
+		t.Errorf("Expected game complete events: %d received: %d", 1, completed)
+	}
+
+	result := game.Result()
+	if !result.Completed || result.Rows != 2 || result.Elapsed != time.Second {
+		t.Errorf("Expected completed sprint of 2 rows in 1s received: %+v", result)
+	}
+
+	leaderboard := game.Leaderboard(LeaderboardKey{Mode: SprintMode, LineTarget: 2})
+	if len(leaderboard.Results) != 1 || leaderboard.Results[0] != result {
+		t.Errorf("Expected sprint on leaderboard received: %+v", leaderboard.Results)
+	}
+	if len(game.Leaderboard(ModeOptions(SprintMode).LeaderboardKey()).Results) != 0 {
+		t.Errorf("Expected sprint of 2 rows to be kept off the %d row sprint leaderboard", SprintLines)
+	}
+	if len(game.Leaderboard(LeaderboardKey{Mode: MarathonMode}).Results) != 0 {
+		t.Error("Expected sprint to be kept off the marathon leaderboard")
+	}
+}
+
+func TestUltraFinishesAtTimeLimit(t *testing.T) {
+
+	options := ModeOptions(UltraMode)
+	options.TimeLimit = ShortUltraDuration
+	game := newTestGame(options, 19)
+
+	states := make([]GameState, 0)
+	game.Subscribe(EventListenerFunc(func(event Event) {
+		if event.Type == StateChangedEvent {
+			states = append(states, event.State)
+		}
+	}))
+
+	// slow the game down so it can't top out
+	game.options.Gravity = GravityCurve{1}
+	game.Tick(ShortUltraDuration - FrameDuration)
+	if game.GetState() != Playing {
+		t.Fatalf("Expected state: %d received: %d", Playing, game.GetState())
+	}
+
+	game.Tick(time.Second)
+	if game.GetState() != Finished {
+		t.Errorf("Expected state: %d received: %d", Finished, game.GetState())
+	}
+	if states[len(states)-1] != Finished {
+		t.Errorf("Expected last state change: %d received: %d", Finished, states[len(states)-1])
+	}
+
+	result := game.Result()
+	if !result.Completed || result.Elapsed != ShortUltraDuration {
+		t.Errorf("Expected completed ultra of %s received: %+v", ShortUltraDuration, result)
+	}
+	if len(game.Leaderboard(options.LeaderboardKey()).Results) != 1 {
+		t.Error("Expected finished ultra on the leaderboard")
+	}
+	if len(game.Leaderboard(ModeOptions(UltraMode).LeaderboardKey()).Results) != 0 {
+		t.Errorf("Expected ultra of %s to be kept off the %s ultra leaderboard", ShortUltraDuration, UltraDuration)
+	}
+}
+
+func TestLostGamesWithGoalsAreNotRanked(t *testing.T) {
+
+	game := newTestGame(ModeOptions(UltraMode), 19)
+
+	game.EndGame()
+
+	if game.GetState() != GameOver || game.Result().Completed {
+		t.Errorf("Expected lost game received state: %d result: %+v", game.GetState(), game.Result())
+	}
+	if len(game.Leaderboard(ModeOptions(UltraMode).LeaderboardKey()).Results) != 0 {
+		t.Error("Expected lost ultra to be kept off the leaderboard")
+	}
+}
@@ -1,30 +1,60 @@
 package domain
 
-import (
-	"fmt"
-	"math/rand"
-)
-
 type Player struct {
 	Score     int
 	Level     int
 	TotalRows int
-	state     PlayerState
-	X, Y      int
-	shape     *Shape
-	nextShape *Shape
+	// consecutive locks that cleared rows
+	Combo    int
+	MaxCombo int
+	// consecutive difficult clears, four rows or T-spins
+	BackToBack    int
+	MaxBackToBack int
+	state         PlayerState
+	X, Y          int
+	shape         *Shape
+	queue         []*Shape // next shapes to deal, front first
+	previews      int      // length of the queue
+	heldShape     *Shape
+	canHold       bool
+	randomizer    Randomizer
+	dealt         int // shapes dealt by the randomizer
+	// where new shapes appear
+	spawnX, spawnY int
 }
 
-func NewPlayer() *Player {
+// NewPlayer returns a player dealt shapes by the randomizer,
+// spawning them at the top middle of a board of the given size.
+// The next previews shapes wait in a queue, between 1 and MaxPreviews
+func NewPlayer(randomizer Randomizer, size BoardSize, previews int) *Player {
+	if previews < 1 {
+		previews = 1
+	}
+	if previews > MaxPreviews {
+		previews = MaxPreviews
+	}
 	player := &Player{
-		Level:     1,
-		Score:     0,
-		TotalRows: 0,
-		state:     Alive,
-		X:         BoardWidth / 2,
-		Y:         BoardHeight - 3,
-		shape:     nil,
-		nextShape: nil,
+		Level:      1,
+		Score:      0,
+		TotalRows:  0,
+		Combo:      0,
+		BackToBack: 0,
+		state:      Alive,
+		X:          size.Width / 2,
+		Y:          size.Height - 3,
+		shape:      nil,
+		queue:      make([]*Shape, 0, previews),
+		previews:   previews,
+		heldShape:  nil,
+		canHold:    true,
+		randomizer: randomizer,
+		spawnX:     size.Width / 2,
+		spawnY:     size.Height - 3,
+	}
+	// keep the widest shape clear of the right hand wall on narrow boards
+	if player.spawnX > size.Width-5 {
+		player.spawnX = size.Width - 5
+		player.X = player.spawnX
 	}
 
 	return player
@@ -38,8 +68,24 @@ func (p *Player) GetShapeBlocks() []*Block {
 }
 
 func (p *Player) GetNextShapeBlocks() []*Block {
-	if p.nextShape != nil {
-		return p.nextShape.GetBlocks()
+	if len(p.queue) > 0 {
+		return p.queue[0].GetBlocks()
+	}
+	return nil
+}
+
+// GetQueueBlocks returns the blocks of each shape in the next queue, front first
+func (p *Player) GetQueueBlocks() [][]*Block {
+	blocks := make([][]*Block, len(p.queue))
+	for i, shape := range p.queue {
+		blocks[i] = shape.GetBlocks()
+	}
+	return blocks
+}
+
+func (p *Player) GetHeldShapeBlocks() []*Block {
+	if p.heldShape != nil {
+		return p.heldShape.GetBlocks()
 	}
 	return nil
 }
@@ -64,33 +110,81 @@ func (p *Player) RotateBack() {
 	p.shape.RotateBack()
 }
 
+// turn rotates by clockwise quarter turns, negative turns go anticlockwise
+func (p *Player) turn(turns int) {
+	for ; turns > 0; turns-- {
+		p.shape.Rotate()
+	}
+	for ; turns < 0; turns++ {
+		p.shape.RotateBack()
+	}
+}
+
+// setNextRandomShape takes the shape at the front of the queue and
+// tops the queue up from the randomizer, the first call only fills the queue
 func (p *Player) setNextRandomShape() {
-	// copy next shape
-	p.shape = p.nextShape
-	// random colour, not empty or grey
-	colour := BlockColour(rand.Intn(Purple) + 1)
-	shapeType := ShapeType(rand.Intn(T) + 1)
-	switch shapeType {
-	case Square:
-		p.nextShape = SquareShape(colour)
-	case Bar:
-		p.nextShape = BarShape(colour)
-	case LeftL:
-		p.nextShape = LeftLShape(colour)
-	case RightL:
-		p.nextShape = RightLShape(colour)
-	case LeftStep:
-		p.nextShape = LeftStepShape(colour)
-	case RightStep:
-		p.nextShape = RightStepShape(colour)
-	case T:
-		p.nextShape = TShape(colour)
-	default:
-		err := fmt.Sprintf("Unexpected shape type: %d", shapeType)
-		panic(err)
+	p.shape = nil
+	if len(p.queue) > 0 {
+		p.shape = p.queue[0]
+		p.queue = append(p.queue[:0], p.queue[1:]...)
+	}
+	for len(p.queue) < p.previews {
+		colour := p.randomizer.NextColour()
+		shapeType := p.randomizer.NextShapeType()
+		p.queue = append(p.queue, NewShape(shapeType, colour))
+		p.dealt++
 	}
 
+	p.resetPosition()
+}
+
+// hold swaps the current shape with the held shape, the first time
+// a shape is held the next shape is dealt in its place
+func (p *Player) hold() bool {
+	// only one swap per shape
+	if !p.canHold {
+		return false
+	}
+
+	heldShape := p.heldShape
+	// held shapes come back unrotated
+	p.shape.Init()
+	p.heldShape = p.shape
+
+	if heldShape == nil {
+		p.setNextRandomShape()
+	} else {
+		p.shape = heldShape
+		p.resetPosition()
+	}
+
+	p.canHold = false
+	return true
+}
+
+func (p *Player) resetPosition() {
 	// position at top middle of board
-	p.X = BoardWidth / 2
-	p.Y = BoardHeight - 3
+	p.X = p.spawnX
+	p.Y = p.spawnY
+}
+
+// updateStreaks counts a lock towards the combo and back to back streaks
+func (p *Player) updateStreaks(lock LockResult) {
+	if len(lock.Rows) == 0 {
+		// back to back chains survive locks that clear nothing
+		p.Combo = 0
+		return
+	}
+	p.Combo++
+	if p.Combo > p.MaxCombo {
+		p.MaxCombo = p.Combo
+	}
+	if !lock.Difficult() {
+		p.BackToBack = 0
+		return
+	}
+	p.BackToBack++
+	if p.BackToBack > p.MaxBackToBack {
+		p.MaxBackToBack = p.BackToBack
+	}
 }
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Randomizer deals the sequence of shapes and colours for a game.
+// Randomizers created from the same seed must deal identical sequences
+// so games can be replayed exactly.
+type Randomizer interface {
+	// Seed returns the seed the randomizer was created with
+	Seed() int64
+	// NextShapeType returns the type of the next shape to deal
+	NextShapeType() ShapeType
+	// NextColour returns the colour of the next shape to deal
+	NextColour() BlockColour
+}
+
+// NewRandomizer returns a randomizer of the requested type
+func NewRandomizer(randomizerType RandomizerType, seed int64) Randomizer {
+	switch randomizerType {
+	case UniformRandomizer:
+		return NewUniformRandomizer(seed)
+	case BagRandomizer:
+		return NewBagRandomizer(seed)
+	case HistoryRandomizer:
+		return NewHistoryRandomizer(seed, HistoryRetries)
+	default:
+		panic(fmt.Sprintf("Unexpected randomizer type: %d", randomizerType))
+	}
+}
+
+// dealtRandomizer returns a new randomizer that has already dealt a number of shapes,
+// picking up where another randomizer created from the same seed left off
+func dealtRandomizer(randomizerType RandomizerType, seed int64, dealt int) Randomizer {
+	randomizer := NewRandomizer(randomizerType, seed)
+	for i := 0; i < dealt; i++ {
+		// deal in the same order as the player
+		randomizer.NextColour()
+		randomizer.NextShapeType()
+	}
+	return randomizer
+}
+
+// seededRandom is the source of randomness shared by all randomizers
+type seededRandom struct {
+	seed int64
+	rand *rand.Rand
+}
+
+func newSeededRandom(seed int64) seededRandom {
+	return seededRandom{
+		seed: seed,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (s *seededRandom) Seed() int64 {
+	return s.seed
+}
+
+func (s *seededRandom) NextColour() BlockColour {
+	// random colour, not empty or grey
+	return BlockColour(s.rand.Intn(Purple) + 1)
+}
+
+func (s *seededRandom) randomShapeType() ShapeType {
+	return ShapeType(s.rand.Intn(T + 1))
+}
+
+type uniformRandomizer struct {
+	seededRandom
+}
+
+// NewUniformRandomizer returns a randomizer where every shape type
+// has an equal chance of being dealt every time
+func NewUniformRandomizer(seed int64) Randomizer {
+	return &uniformRandomizer{
+		seededRandom: newSeededRandom(seed),
+	}
+}
+
+func (u *uniformRandomizer) NextShapeType() ShapeType {
+	return u.randomShapeType()
+}
+
+type bagRandomizer struct {
+	seededRandom
+	bag []ShapeType
+}
+
+// NewBagRandomizer returns a randomizer that deals every shape type
+// once from a shuffled bag before refilling it, so a shape is never
+// more than 12 shapes away
+func NewBagRandomizer(seed int64) Randomizer {
+	return &bagRandomizer{
+		seededRandom: newSeededRandom(seed),
+	}
+}
+
+func (b *bagRandomizer) NextShapeType() ShapeType {
+	if len(b.bag) == 0 {
+		// refill bag with every shape in a random order
+		for _, n := range b.rand.Perm(T + 1) {
+			b.bag = append(b.bag, ShapeType(n))
+		}
+	}
+
+	shapeType := b.bag[0]
+	b.bag = b.bag[1:]
+	return shapeType
+}
+
+type historyRandomizer struct {
+	seededRandom
+	retries int
+	history []ShapeType
+	first   bool
+}
+
+// NewHistoryRandomizer returns a randomizer that remembers the last
+// four shapes dealt and rerolls up to retries times to avoid
+// dealing one of them again
+func NewHistoryRandomizer(seed int64, retries int) Randomizer {
+	if retries < 1 {
+		retries = 1
+	}
+	return &historyRandomizer{
+		seededRandom: newSeededRandom(seed),
+		retries:      retries,
+		// history starts full of steps so they are unlikely early on
+		history: []ShapeType{LeftStep, RightStep, LeftStep, RightStep},
+		first:   true,
+	}
+}
+
+func (h *historyRandomizer) NextShapeType() ShapeType {
+	var shapeType ShapeType
+
+	if h.first {
+		// never start with a shape that can't be placed without a gap
+		firstShapes := []ShapeType{Bar, LeftL, RightL, T}
+		shapeType = firstShapes[h.rand.Intn(len(firstShapes))]
+		h.first = false
+	} else {
+		for i := 0; i < h.retries; i++ {
+			shapeType = h.randomShapeType()
+			if !h.inHistory(shapeType) {
+				break
+			}
+		}
+	}
+
+	h.history = append(h.history[1:], shapeType)
+	return shapeType
+}
+
+func (h *historyRandomizer) inHistory(shapeType ShapeType) bool {
+	for _, previous := range h.history {
+		if previous == shapeType {
+			return true
+		}
+	}
+	return false
+}
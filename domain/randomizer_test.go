@@ -0,0 +1,100 @@
+package domain
+
+import "testing"
+
+func TestRandomizerIsRepeatable(t *testing.T) {
+
+	first := NewUniformRandomizer(42)
+	second := NewUniformRandomizer(42)
+
+	for i := 0; i < 100; i++ {
+		firstShape, secondShape := first.NextShapeType(), second.NextShapeType()
+		if firstShape != secondShape {
+			t.Fatalf("Shape %d differs Expected: %d got: %d", i, firstShape, secondShape)
+		}
+		firstColour, secondColour := first.NextColour(), second.NextColour()
+		if firstColour != secondColour {
+			t.Fatalf("Colour %d differs Expected: %d got: %d", i, firstColour, secondColour)
+		}
+	}
+}
+
+func TestRandomizerDealsAllShapes(t *testing.T) {
+
+	randomizer := NewUniformRandomizer(1)
+	dealt := make(map[ShapeType]bool)
+
+	for i := 0; i < 1000; i++ {
+		shapeType := randomizer.NextShapeType()
+		if shapeType < Square || shapeType > T {
+			t.Fatalf("Unexpected shape type: %d", shapeType)
+		}
+		dealt[shapeType] = true
+
+		colour := randomizer.NextColour()
+		if colour == Empty || colour == Grey {
+			t.Fatalf("Unexpected colour: %d", colour)
+		}
+	}
+
+	if len(dealt) != T+1 {
+		t.Errorf("Expected all %d shape types to be dealt got: %d", T+1, len(dealt))
+	}
+}
+
+func TestBagRandomizerDealsEveryShapePerBag(t *testing.T) {
+
+	randomizer := NewBagRandomizer(7)
+
+	for bag := 0; bag < 20; bag++ {
+		dealt := make(map[ShapeType]bool)
+		for i := 0; i <= T; i++ {
+			dealt[randomizer.NextShapeType()] = true
+		}
+		if len(dealt) != T+1 {
+			t.Fatalf("Bag %d Expected %d different shapes got: %d", bag, T+1, len(dealt))
+		}
+	}
+}
+
+func TestHistoryRandomizerAvoidsRecentShapes(t *testing.T) {
+
+	// with plenty of retries a shape is never dealt twice within 4 shapes
+	randomizer := NewHistoryRandomizer(3, 100)
+
+	first := randomizer.NextShapeType()
+	if first == Square || first == LeftStep || first == RightStep {
+		t.Errorf("Unexpected first shape: %d", first)
+	}
+
+	dealt := []ShapeType{first}
+	for i := 0; i < 200; i++ {
+		shapeType := randomizer.NextShapeType()
+		start := len(dealt) - 4
+		if start < 0 {
+			start = 0
+		}
+		for _, previous := range dealt[start:] {
+			if previous == shapeType {
+				t.Fatalf("Shape %d: %d was dealt within last 4 shapes %v", i, shapeType, dealt[start:])
+			}
+		}
+		dealt = append(dealt, shapeType)
+	}
+}
+
+func TestNewRandomizerTypes(t *testing.T) {
+
+	for _, randomizerType := range []RandomizerType{UniformRandomizer, BagRandomizer, HistoryRandomizer} {
+		first := NewRandomizer(randomizerType, 5)
+		second := NewRandomizer(randomizerType, 5)
+		if first.Seed() != 5 {
+			t.Errorf("Randomizer %d Expected seed: 5 got: %d", randomizerType, first.Seed())
+		}
+		for i := 0; i < 50; i++ {
+			if first.NextShapeType() != second.NextShapeType() {
+				t.Fatalf("Randomizer %d shape %d differs", randomizerType, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// replayMagic starts every replay file
+const replayMagic = "TLRP"
+
+// ReplayInput is a player command and the frame it was carried out on
+type ReplayInput struct {
+	Frame   int
+	Command Command
+}
+
+// Replay records the seed, options and player commands of a game
+// so it can be played back exactly through a headless game.
+// Games are played back with the scorer of their mode
+type Replay struct {
+	Version    int
+	Seed       int64
+	Mode       GameMode
+	Randomizer RandomizerType
+	BoardSize  BoardSize
+	LineTarget int
+	TimeLimit  time.Duration
+	LockDelay  int
+	LockResets int
+	Previews   int
+	Gravity    GravityCurve
+	// frames played when the recording stopped
+	Frames int
+	Inputs []ReplayInput
+}
+
+// startRecording records a new game from its first frame
+func (g *Game) startRecording() {
+	g.replay = Replay{
+		Version:    ReplayVersion,
+		Seed:       g.Player.randomizer.Seed(),
+		Mode:       g.options.Mode,
+		Randomizer: g.options.Randomizer,
+		BoardSize:  g.options.BoardSize,
+		LineTarget: g.options.LineTarget,
+		TimeLimit:  g.options.TimeLimit,
+		LockDelay:  g.options.LockDelay,
+		LockResets: g.options.LockResets,
+		Previews:   g.options.Previews,
+		Gravity:    append(GravityCurve(nil), g.options.Gravity...),
+	}
+}
+
+// record adds a command carried out on the current frame to the recording
+func (g *Game) record(command Command) {
+	g.replay.Inputs = append(g.replay.Inputs, ReplayInput{Frame: g.frame, Command: command})
+}
+
+// Replay returns the recording of the current game up to the current frame
+func (g *Game) Replay() Replay {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.recording()
+}
+
+func (g *Game) recording() Replay {
+	replay := g.replay
+	replay.Frames = g.frame
+	replay.Inputs = append([]ReplayInput(nil), g.replay.Inputs...)
+	return replay
+}
+
+// SetReplayPath sets the file the recording of each game is saved to when it ends
+func (g *Game) SetReplayPath(path string) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.replayPath = path
+}
+
+// ReplayPath returns the file the recording of the last game was saved to
+func (g *Game) ReplayPath() string {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.replayPath
+}
+
+// options returns the options the recorded game was played with
+func (r Replay) options() GameOptions {
+	options := ModeOptions(r.Mode)
+	options.Randomizer = r.Randomizer
+	options.BoardSize = r.BoardSize
+	options.LineTarget = r.LineTarget
+	options.TimeLimit = r.TimeLimit
+	options.LockDelay = r.LockDelay
+	options.LockResets = r.LockResets
+	options.Previews = r.Previews
+	options.Gravity = r.Gravity
+	return options
+}
+
+// NewGame returns a headless game ready to play back the replay from its first frame
+func (r Replay) NewGame() *Game {
+	g := NewGameWithOptions(r.options())
+	g.randomizer = NewRandomizer(r.Randomizer, r.Seed)
+	g.mutex.Lock()
+	defer g.unlock()
+	g.newGame()
+	return g
+}
+
+// Play plays back the whole replay through a headless game,
+// returning the game as it was when the recording stopped
+func (r Replay) Play() *Game {
+	g := r.NewGame()
+	g.mutex.Lock()
+	defer g.unlock()
+	r.playTo(g, r.Frames, 0)
+	return g
+}
+
+// playTo advances a game playing back the replay to a frame,
+// carrying out the recorded inputs from next along the way.
+// It returns the index of the next input to carry out
+func (r Replay) playTo(g *Game, frame, next int) int {
+	for g.state == Playing {
+		for next < len(r.Inputs) && r.Inputs[next].Frame <= g.frame && g.state == Playing {
+			command := r.Inputs[next].Command
+			g.record(command)
+			g.execute(command)
+			next++
+		}
+		if g.frame >= frame || g.state != Playing {
+			break
+		}
+		g.step()
+	}
+	return next
+}
+
+// MarshalBinary encodes the replay compactly,
+// inputs are stored as the frames since the previous input
+func (r Replay) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(replayMagic)
+
+	varint := make([]byte, binary.MaxVarintLen64)
+	putInt := func(value int64) {
+		n := binary.PutVarint(varint, value)
+		buf.Write(varint[:n])
+	}
+
+	putInt(ReplayVersion)
+	putInt(r.Seed)
+	putInt(int64(r.Mode))
+	putInt(int64(r.Randomizer))
+	putInt(int64(r.BoardSize.Width))
+	putInt(int64(r.BoardSize.Height))
+	putInt(int64(r.LineTarget))
+	putInt(int64(r.TimeLimit))
+	putInt(int64(r.LockDelay))
+	putInt(int64(r.LockResets))
+	putInt(int64(r.Previews))
+	putInt(int64(len(r.Gravity)))
+	for _, gravity := range r.Gravity {
+		putInt(int64(gravity))
+	}
+	putInt(int64(r.Frames))
+	putInt(int64(len(r.Inputs)))
+	frame := 0
+	for _, input := range r.Inputs {
+		putInt(int64(input.Frame - frame))
+		buf.WriteByte(byte(input.Command))
+		frame = input.Frame
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a replay encoded by MarshalBinary
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	reader := bufio.NewReader(bytes.NewReader(data))
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != replayMagic {
+		return errors.New("not a replay")
+	}
+
+	var err error
+	getInt := func() int64 {
+		if err != nil {
+			return 0
+		}
+		var value int64
+		value, err = binary.ReadVarint(reader)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return value
+	}
+
+	replay := Replay{Version: int(getInt())}
+	if err == nil && replay.Version != ReplayVersion {
+		return fmt.Errorf("unsupported replay version %d", replay.Version)
+	}
+	replay.Seed = getInt()
+	replay.Mode = GameMode(getInt())
+	replay.Randomizer = RandomizerType(getInt())
+	replay.BoardSize.Width = int(getInt())
+	replay.BoardSize.Height = int(getInt())
+	replay.LineTarget = int(getInt())
+	replay.TimeLimit = time.Duration(getInt())
+	replay.LockDelay = int(getInt())
+	replay.LockResets = int(getInt())
+	replay.Previews = int(getInt())
+	if err == nil && (replay.Previews < 1 || replay.Previews > MaxPreviews) {
+		return fmt.Errorf("corrupt replay previewing %d shapes", replay.Previews)
+	}
+	levels := getInt()
+	if err == nil && (levels < 1 || levels > int64(len(data))) {
+		return fmt.Errorf("corrupt replay gravity of %d levels", levels)
+	}
+	replay.Gravity = make(GravityCurve, 0, levels)
+	for i := int64(0); i < levels && err == nil; i++ {
+		replay.Gravity = append(replay.Gravity, int(getInt()))
+	}
+	replay.Frames = int(getInt())
+	inputs := getInt()
+	if err == nil && (inputs < 0 || inputs > int64(len(data))) {
+		return fmt.Errorf("corrupt replay of %d inputs", inputs)
+	}
+	replay.Inputs = make([]ReplayInput, 0, inputs)
+	frame := 0
+	for i := int64(0); i < inputs && err == nil; i++ {
+		frame += int(getInt())
+		var command byte
+		if err == nil {
+			command, err = reader.ReadByte()
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+		replay.Inputs = append(replay.Inputs, ReplayInput{Frame: frame, Command: Command(command)})
+	}
+	if err != nil {
+		return err
+	}
+
+	if replay.Randomizer < UniformRandomizer || replay.Randomizer > HistoryRandomizer {
+		return fmt.Errorf("unexpected replay randomizer type: %d", replay.Randomizer)
+	}
+	if replay.BoardSize.Width < 1 || replay.BoardSize.Height < 1 {
+		return fmt.Errorf("unexpected replay board size: %dx%d", replay.BoardSize.Width, replay.BoardSize.Height)
+	}
+	*r = replay
+	return nil
+}
+
+// ReadReplay reads a replay from a file
+func ReadReplay(path string) (Replay, error) {
+	var replay Replay
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return replay, err
+	}
+	if err := replay.UnmarshalBinary(data); err != nil {
+		return replay, fmt.Errorf("corrupt replay %s: %s", path, err)
+	}
+	return replay, nil
+}
+
+// Write saves the replay to a file
+func (r Replay) Write(path string) error {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
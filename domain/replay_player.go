@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+// keyframe is a copy of a game being played back,
+// seeking re-simulates from the nearest keyframe before the frame sought
+type keyframe struct {
+	game *Game
+	next int // next input to carry out
+}
+
+// ReplayPlayer plays back a replay through a headless game in real time.
+// It can be paused, sped up or slowed down and seek to any frame
+type ReplayPlayer struct {
+	mutex     sync.Mutex
+	replay    Replay
+	game      *Game
+	next      int // next input to carry out
+	keyframes []keyframe
+	paused    bool
+	speed     float64
+	frameTime time.Duration
+}
+
+// NewReplayPlayer returns a player ready to play back a replay from its first frame
+func NewReplayPlayer(replay Replay) *ReplayPlayer {
+	p := &ReplayPlayer{
+		replay: replay,
+		game:   replay.NewGame(),
+		speed:  1,
+	}
+	p.keyframes = []keyframe{{game: p.game.clone()}}
+	return p
+}
+
+// Game returns the game being played back, it is the same game throughout
+// so renderers can keep drawing it after a seek
+func (p *ReplayPlayer) Game() *Game {
+	return p.game
+}
+
+// Frames returns the number of frames in the replay
+func (p *ReplayPlayer) Frames() int {
+	return p.replay.Frames
+}
+
+// Frame returns the frame the game has been played back to
+func (p *ReplayPlayer) Frame() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.game.Frame()
+}
+
+// Ended returns true once the whole replay has been played back
+func (p *ReplayPlayer) Ended() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.ended()
+}
+
+func (p *ReplayPlayer) ended() bool {
+	return p.game.Frame() >= p.replay.Frames || p.game.GetState() != Playing
+}
+
+// SetPaused pauses or resumes playback
+func (p *ReplayPlayer) SetPaused(paused bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.paused = paused
+}
+
+// Paused returns true while playback is paused
+func (p *ReplayPlayer) Paused() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.paused
+}
+
+// SetSpeed changes how fast the replay plays back,
+// limited to between MinReplaySpeed and MaxReplaySpeed
+func (p *ReplayPlayer) SetSpeed(speed float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if speed < MinReplaySpeed {
+		speed = MinReplaySpeed
+	}
+	if speed > MaxReplaySpeed {
+		speed = MaxReplaySpeed
+	}
+	p.speed = speed
+}
+
+// Speed returns how fast the replay plays back, 1 is real time
+func (p *ReplayPlayer) Speed() float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.speed
+}
+
+// Tick plays back the replay by dt of real time at the current speed.
+// Any time left over is carried into the next call
+func (p *ReplayPlayer) Tick(dt time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.paused || p.ended() {
+		return
+	}
+
+	p.frameTime += time.Duration(float64(dt) * p.speed)
+	frames := int(p.frameTime / FrameDuration)
+	p.frameTime -= time.Duration(frames) * FrameDuration
+	p.seek(p.game.Frame() + frames)
+}
+
+// Seek plays back the replay to a frame,
+// seeking backwards re-simulates from the nearest keyframe
+func (p *ReplayPlayer) Seek(frame int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.frameTime = 0
+	p.seek(frame)
+}
+
+func (p *ReplayPlayer) seek(frame int) {
+	if frame < 0 {
+		frame = 0
+	}
+	if frame > p.replay.Frames {
+		frame = p.replay.Frames
+	}
+
+	// carry on from the current frame unless a keyframe is closer
+	nearest := p.keyframes[0]
+	for _, keyframe := range p.keyframes {
+		if keyframe.game.frame <= frame {
+			nearest = keyframe
+		}
+	}
+	current := p.game.Frame()
+	if frame < current || nearest.game.frame > current {
+		p.game.mutex.Lock()
+		p.game.restore(nearest.game)
+		p.game.unlock()
+		p.next = nearest.next
+	}
+
+	p.game.mutex.Lock()
+	defer p.game.unlock()
+	for p.game.frame < frame && p.game.state == Playing {
+		// stop at each keyframe along the way
+		target := (p.game.frame/KeyframeFrames + 1) * KeyframeFrames
+		if target > frame {
+			target = frame
+		}
+		p.next = p.replay.playTo(p.game, target, p.next)
+		p.addKeyframe()
+	}
+	if p.game.frame == frame {
+		// carry out inputs on the frame sought
+		p.next = p.replay.playTo(p.game, frame, p.next)
+	}
+}
+
+// addKeyframe keeps a copy of the game on each keyframe the first time it is reached
+func (p *ReplayPlayer) addKeyframe() {
+	last := p.keyframes[len(p.keyframes)-1]
+	if p.game.frame%KeyframeFrames != 0 || p.game.frame <= last.game.frame {
+		return
+	}
+	p.keyframes = append(p.keyframes, keyframe{game: p.game.clone(), next: p.next})
+}
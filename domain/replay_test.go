@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// playRandomGame plays random commands between random pauses
+func playRandomGame(game *Game, moves int) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < moves && game.GetState() == Playing; i++ {
+		game.Tick(time.Duration(random.Intn(20)) * FrameDuration)
+		switch command := Command(random.Intn(int(SoftDropOffCommand) + 1)); command {
+		case SoftDropOnCommand:
+			game.SetSoftDrop(true)
+		case SoftDropOffCommand:
+			game.SetSoftDrop(false)
+		case HardDropCommand:
+			game.HardDrop()
+		default:
+			game.Execute(command)
+		}
+	}
+}
+
+func sameBoard(a, b *Game) bool {
+	if len(a.board.cells) != len(b.board.cells) {
+		return false
+	}
+	for x := range a.board.cells {
+		for y := range a.board.cells[x] {
+			if a.board.cells[x][y].Colour != b.board.cells[x][y].Colour {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestReplayReproducesGame(t *testing.T) {
+
+	for _, mode := range []GameMode{MarathonMode, SprintMode, ZenMode} {
+		options := ModeOptions(mode)
+		options.BoardSize = NarrowBoard
+		options.Previews = 5
+		game := newTestGame(options, 22)
+
+		playRandomGame(game, 400)
+		recorded := game.Replay()
+
+		data, err := recorded.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var replay Replay
+		if err := replay.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(replay, recorded) {
+			t.Fatalf("Expected decoded replay: %+v received: %+v", recorded, replay)
+		}
+
+		replayed := replay.Play()
+		if !sameBoard(game, replayed) {
+			t.Errorf("Expected replayed %d board to match", mode)
+		}
+		if replayed.Player.Score != game.Player.Score || replayed.Player.TotalRows != game.Player.TotalRows {
+			t.Errorf("Expected score: %d rows: %d received score: %d rows: %d",
+				game.Player.Score, game.Player.TotalRows, replayed.Player.Score, replayed.Player.TotalRows)
+		}
+		if replayed.GetState() != game.GetState() || replayed.Frame() != game.Frame() {
+			t.Errorf("Expected state: %d at frame: %d received state: %d at frame: %d",
+				game.GetState(), game.Frame(), replayed.GetState(), replayed.Frame())
+		}
+		if len(replayed.Snapshot().Queue) != options.Previews {
+			t.Errorf("Expected next queue of %d shapes received: %d", options.Previews, len(replayed.Snapshot().Queue))
+		}
+		if !reflect.DeepEqual(replayed.Replay(), recorded) {
+			t.Error("Expected the replayed game to record the same replay")
+		}
+	}
+}
+
+func TestReplayKeepsSoftDropAcrossSuspend(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 22)
+	game.SetSoftDrop(true)
+	game.SuspendGame()
+	game.SetSoftDrop(false)
+	resumed(game)
+	game.Tick(30 * FrameDuration)
+
+	replayed := game.Replay().Play()
+	if replayed.Player.Y != game.Player.Y || !sameBoard(game, replayed) {
+		t.Errorf("Expected replayed shape at y: %d received: %d", game.Player.Y, replayed.Player.Y)
+	}
+}
+
+func TestReadReplayRejectsCorruptFiles(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 22)
+	playRandomGame(game, 20)
+	data, _ := game.Replay().MarshalBinary()
+
+	corrupt := map[string][]byte{
+		"truncated": data[:len(data)-1],
+		"magic":     append([]byte("JUNK"), data[len(replayMagic):]...),
+		"version":   append([]byte(replayMagic), append([]byte{ReplayVersion*2 + 2}, data[len(replayMagic)+1:]...)...),
+	}
+	for name, content := range corrupt {
+		var replay Replay
+		if err := replay.UnmarshalBinary(content); err == nil {
+			t.Errorf("Expected an error reading a %s replay", name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), ReplayFile)
+	if err := game.Replay().Write(path); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(path)
+	if err != nil || replay.Frames != game.Frame() {
+		t.Errorf("Expected replay of %d frames received: %d %v", game.Frame(), replay.Frames, err)
+	}
+}
+
+func TestReplayPlayerSeeksFromKeyframes(t *testing.T) {
+
+	game := newTestGame(ModeOptions(ZenMode), 23)
+	playRandomGame(game, 400)
+	replay := game.Replay()
+
+	// played straight through without keyframes
+	playedTo := func(frame int) *Game {
+		played := replay.NewGame()
+		played.mutex.Lock()
+		defer played.unlock()
+		replay.playTo(played, frame, 0)
+		return played
+	}
+
+	player := NewReplayPlayer(replay)
+	for _, frame := range []int{replay.Frames, replay.Frames / 3, KeyframeFrames, 0, replay.Frames/2 + 1} {
+		player.Seek(frame)
+		expected := playedTo(frame)
+		if player.Frame() != frame || !sameBoard(player.Game(), expected) ||
+			player.Game().Player.Score != expected.Player.Score {
+			t.Errorf("Expected frame: %d score: %d received frame: %d score: %d",
+				frame, expected.Player.Score, player.Frame(), player.Game().Player.Score)
+		}
+	}
+	if len(player.keyframes) != replay.Frames/KeyframeFrames+1 {
+		t.Errorf("Expected keyframes: %d received: %d", replay.Frames/KeyframeFrames+1, len(player.keyframes))
+	}
+
+	player.Seek(replay.Frames)
+	if !player.Ended() || !sameBoard(player.Game(), game) || player.Game().Player.Score != game.Player.Score {
+		t.Error("Expected the end of the replay to match the recorded game")
+	}
+}
+
+func TestReplayPlayerSpeed(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 23)
+	game.Tick(10 * time.Second)
+	player := NewReplayPlayer(game.Replay())
+
+	player.SetSpeed(2)
+	player.Tick(time.Second)
+	if player.Frame() != 2*FramesPerSecond {
+		t.Errorf("Expected frame: %d received: %d", 2*FramesPerSecond, player.Frame())
+	}
+
+	player.SetPaused(true)
+	player.Tick(time.Second)
+	if player.Frame() != 2*FramesPerSecond {
+		t.Errorf("Expected paused at frame: %d received: %d", 2*FramesPerSecond, player.Frame())
+	}
+
+	player.SetPaused(false)
+	player.SetSpeed(100)
+	if player.Speed() != MaxReplaySpeed {
+		t.Errorf("Expected speed: %v received: %v", float64(MaxReplaySpeed), player.Speed())
+	}
+	player.Tick(time.Second)
+	if !player.Ended() || player.Frame() != game.Frame() {
+		t.Errorf("Expected replay to end at frame: %d received: %d", game.Frame(), player.Frame())
+	}
+}
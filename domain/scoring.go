@@ -0,0 +1,83 @@
+package domain
+
+// LockResult describes what happened when a shape locked onto the board
+type LockResult struct {
+	ShapeType ShapeType
+	// T-spin the shape locked with
+	TSpin TSpin
+	// board rows cleared from bottom to top
+	Rows []int
+	// level the shape locked at
+	Level int
+	// rows soft dropped and hard dropped by the player
+	SoftDropRows int
+	HardDropRows int
+	// consecutive clearing locks before this one, 0 if there is no combo
+	Combo int
+	// difficult clear following another difficult clear
+	BackToBack bool
+	// points awarded
+	Score ScoreBreakdown
+}
+
+// Difficult returns true for clears that build back to back chains
+func (l LockResult) Difficult() bool {
+	if l.TSpin != NoTSpin {
+		return len(l.Rows) > 0
+	}
+	return len(l.Rows) >= 4
+}
+
+// ScoreBreakdown itemises the points awarded for a lock
+type ScoreBreakdown struct {
+	Rows       int
+	BackToBack int
+	Combo      int
+	Drop       int
+	Total      int
+}
+
+// Scorer awards points for each shape that locks
+type Scorer interface {
+	Score(lock LockResult) ScoreBreakdown
+}
+
+// ScorerFunc adapts a function to a Scorer
+type ScorerFunc func(lock LockResult) ScoreBreakdown
+
+func (f ScorerFunc) Score(lock LockResult) ScoreBreakdown {
+	return f(lock)
+}
+
+// guidelineRowScores are the points per level for clearing 0 to 4 rows
+var guidelineRowScores = []int{0, 100, 300, 500, 800}
+
+// guidelineTSpinScores are the points per level for T-spins clearing 0 to 3 rows
+var guidelineTSpinScores = map[TSpin][]int{
+	MiniTSpin: {100, 200, 400},
+	FullTSpin: {400, 800, 1200, 1600},
+}
+
+// GuidelineScorer scores clears by rows cleared, T-spins and level,
+// with bonuses for combos and back to back difficult clears
+var GuidelineScorer = ScorerFunc(func(lock LockResult) ScoreBreakdown {
+	breakdown := ScoreBreakdown{}
+
+	rowScores := guidelineRowScores
+	if lock.TSpin != NoTSpin {
+		rowScores = guidelineTSpinScores[lock.TSpin]
+	}
+	rows := len(lock.Rows)
+	if rows >= len(rowScores) {
+		rows = len(rowScores) - 1
+	}
+	breakdown.Rows = rowScores[rows] * lock.Level
+	if lock.BackToBack {
+		breakdown.BackToBack = breakdown.Rows / 2
+	}
+	breakdown.Combo = ComboScore * lock.Combo * lock.Level
+	breakdown.Drop = lock.SoftDropRows*SoftDropScorePerRow + lock.HardDropRows*HardDropScorePerRow
+
+	breakdown.Total = breakdown.Rows + breakdown.BackToBack + breakdown.Combo + breakdown.Drop
+	return breakdown
+})
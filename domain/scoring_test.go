@@ -0,0 +1,81 @@
+package domain
+
+import "testing"
+
+func TestGuidelineScorer(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		lock     LockResult
+		expected ScoreBreakdown
+	}{
+		{
+			name:     "no rows",
+			lock:     LockResult{Level: 3, HardDropRows: 10},
+			expected: ScoreBreakdown{Drop: 20, Total: 20},
+		},
+		{
+			name:     "single",
+			lock:     LockResult{Level: 2, Rows: []int{1}, SoftDropRows: 4},
+			expected: ScoreBreakdown{Rows: 200, Drop: 4, Total: 204},
+		},
+		{
+			name:     "four rows back to back",
+			lock:     LockResult{Level: 1, Rows: []int{1, 2, 3, 4}, BackToBack: true},
+			expected: ScoreBreakdown{Rows: 800, BackToBack: 400, Total: 1200},
+		},
+		{
+			name:     "double in a combo",
+			lock:     LockResult{Level: 2, Rows: []int{1, 2}, Combo: 3},
+			expected: ScoreBreakdown{Rows: 600, Combo: 300, Total: 900},
+		},
+	}
+
+	for _, test := range tests {
+		breakdown := GuidelineScorer.Score(test.lock)
+		if breakdown != test.expected {
+			t.Errorf("%s Expected: %+v received: %+v", test.name, test.expected, breakdown)
+		}
+	}
+}
+
+func TestLocksTrackCombosAndBackToBack(t *testing.T) {
+
+	game := newTestGame(DefaultGameOptions(), 12)
+
+	// lock a flat bar into the gap of an almost complete row
+	lockBar := func(rows int) LockResult {
+		for y := 1; y <= rows; y++ {
+			fillRow(&game.board, y, 1)
+		}
+		game.Player.shape = BarShape(Blue)
+		game.Player.shape.Rotate()
+		game.Player.X = 0
+		game.Player.Y = 1
+		game.HardDrop()
+		return game.lastLock
+	}
+
+	first := lockBar(4)
+	if first.Combo != 0 || first.BackToBack {
+		t.Errorf("Expected no combo or back to back received: %+v", first)
+	}
+
+	second := lockBar(4)
+	if second.Combo != 1 || !second.BackToBack {
+		t.Errorf("Expected combo and back to back received: %+v", second)
+	}
+
+	third := lockBar(0)
+	if len(third.Rows) != 0 || third.Combo != 0 {
+		t.Errorf("Expected no rows or combo received: %+v", third)
+	}
+	if game.Player.BackToBack != 2 || game.Player.MaxCombo != 2 {
+		t.Errorf("Expected back to back: 2 max combo: 2 received: %d %d", game.Player.BackToBack, game.Player.MaxCombo)
+	}
+
+	single := lockBar(1)
+	if single.BackToBack || game.Player.BackToBack != 0 || game.Player.Combo != 1 {
+		t.Errorf("Expected single to break back to back received: %+v", single)
+	}
+}
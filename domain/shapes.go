@@ -1,9 +1,13 @@
 package domain
 
+import "fmt"
+
 type View []*Block
 
 type Shape struct {
+	shapeType ShapeType
 	views     []View
+	rotations []RotationState // rotation state of each view
 	viewIndex int
 	visible   bool
 }
@@ -27,13 +31,59 @@ func (s *Shape) RotateBack() {
 	}
 }
 
+// Rotation returns the rotation state of the current view,
+// this is stable for each view so it can be used to look up kicks
+func (s *Shape) Rotation() RotationState {
+	return s.rotations[s.viewIndex]
+}
+
+func (s *Shape) Type() ShapeType {
+	return s.shapeType
+}
+
 func (s *Shape) GetBlocks() []*Block {
 	return s.views[s.viewIndex]
 }
 
+// copy returns a new shape of the same type, colour and rotation
+func (s *Shape) copy() *Shape {
+	if s == nil {
+		return nil
+	}
+	shape := NewShape(s.shapeType, s.views[0][0].Colour)
+	shape.viewIndex = s.viewIndex
+	shape.visible = s.visible
+	return shape
+}
+
+// NewShape returns a new shape of the requested type
+func NewShape(shapeType ShapeType, colour BlockColour) *Shape {
+	switch shapeType {
+	case Square:
+		return SquareShape(colour)
+	case Bar:
+		return BarShape(colour)
+	case LeftL:
+		return LeftLShape(colour)
+	case RightL:
+		return RightLShape(colour)
+	case LeftStep:
+		return LeftStepShape(colour)
+	case RightStep:
+		return RightStepShape(colour)
+	case T:
+		return TShape(colour)
+	default:
+		err := fmt.Sprintf("Unexpected shape type: %d", shapeType)
+		panic(err)
+	}
+}
+
 func SquareShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: Square,
+		rotations: []RotationState{Rotation0},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -50,6 +100,8 @@ func SquareShape(colour BlockColour) *Shape {
 func BarShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: Bar,
+		rotations: []RotationState{Rotation2, RotationL},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -72,6 +124,8 @@ func BarShape(colour BlockColour) *Shape {
 func LeftLShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: LeftL,
+		rotations: []RotationState{Rotation0, RotationR, Rotation2, RotationL},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -82,10 +136,10 @@ func LeftLShape(colour BlockColour) *Shape {
 				&Block{X: 2, Y: 1, Colour: colour},
 			},
 			View{
-				&Block{X: 2, Y: 0, Colour: colour},
-				&Block{X: 2, Y: 1, Colour: colour},
-				&Block{X: 2, Y: 2, Colour: colour},
-				&Block{X: 1, Y: 2, Colour: colour},
+				&Block{X: 0, Y: 0, Colour: colour},
+				&Block{X: 0, Y: 1, Colour: colour},
+				&Block{X: 0, Y: 2, Colour: colour},
+				&Block{X: 1, Y: 0, Colour: colour},
 			},
 			View{
 				&Block{X: 0, Y: 2, Colour: colour},
@@ -94,10 +148,10 @@ func LeftLShape(colour BlockColour) *Shape {
 				&Block{X: 0, Y: 1, Colour: colour},
 			},
 			View{
-				&Block{X: 0, Y: 0, Colour: colour},
-				&Block{X: 0, Y: 1, Colour: colour},
-				&Block{X: 0, Y: 2, Colour: colour},
-				&Block{X: 1, Y: 0, Colour: colour},
+				&Block{X: 2, Y: 0, Colour: colour},
+				&Block{X: 2, Y: 1, Colour: colour},
+				&Block{X: 2, Y: 2, Colour: colour},
+				&Block{X: 1, Y: 2, Colour: colour},
 			},
 		},
 	}
@@ -106,6 +160,8 @@ func LeftLShape(colour BlockColour) *Shape {
 func RightLShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: RightL,
+		rotations: []RotationState{Rotation0, RotationR, Rotation2, RotationL},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -116,10 +172,10 @@ func RightLShape(colour BlockColour) *Shape {
 				&Block{X: 0, Y: 1, Colour: colour},
 			},
 			View{
-				&Block{X: 2, Y: 0, Colour: colour},
-				&Block{X: 2, Y: 1, Colour: colour},
-				&Block{X: 2, Y: 2, Colour: colour},
-				&Block{X: 1, Y: 0, Colour: colour},
+				&Block{X: 0, Y: 0, Colour: colour},
+				&Block{X: 0, Y: 1, Colour: colour},
+				&Block{X: 0, Y: 2, Colour: colour},
+				&Block{X: 1, Y: 2, Colour: colour},
 			},
 			View{
 				&Block{X: 0, Y: 2, Colour: colour},
@@ -128,10 +184,10 @@ func RightLShape(colour BlockColour) *Shape {
 				&Block{X: 2, Y: 1, Colour: colour},
 			},
 			View{
-				&Block{X: 0, Y: 0, Colour: colour},
-				&Block{X: 0, Y: 1, Colour: colour},
-				&Block{X: 0, Y: 2, Colour: colour},
-				&Block{X: 1, Y: 2, Colour: colour},
+				&Block{X: 2, Y: 0, Colour: colour},
+				&Block{X: 2, Y: 1, Colour: colour},
+				&Block{X: 2, Y: 2, Colour: colour},
+				&Block{X: 1, Y: 0, Colour: colour},
 			},
 		},
 	}
@@ -140,6 +196,8 @@ func RightLShape(colour BlockColour) *Shape {
 func LeftStepShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: LeftStep,
+		rotations: []RotationState{Rotation2, RotationL},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -162,6 +220,8 @@ func LeftStepShape(colour BlockColour) *Shape {
 func RightStepShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: RightStep,
+		rotations: []RotationState{Rotation2, RotationL},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -184,6 +244,8 @@ func RightStepShape(colour BlockColour) *Shape {
 func TShape(colour BlockColour) *Shape {
 
 	return &Shape{
+		shapeType: T,
+		rotations: []RotationState{Rotation2, RotationL, Rotation0, RotationR},
 		viewIndex: 0,
 		visible:   false,
 		views: []View{
@@ -0,0 +1,126 @@
+package domain
+
+import "time"
+
+// Snapshot is a read only copy of the game state at a single point in time.
+// Renderers draw from a snapshot so they never race a running game
+type Snapshot struct {
+	State        GameState
+	Mode         GameMode
+	Board        [][]Block
+	X, Y         int
+	GhostX       int
+	GhostY       int
+	Shape        []Block
+	Queue        [][]Block
+	HeldShape    []Block
+	Score        int
+	Level        int
+	TotalRows    int
+	LineTarget   int
+	Elapsed      time.Duration
+	TimeLimit    time.Duration
+	Combo        int
+	BackToBack   int
+	LastLock     LockResult
+	AudioPlaying bool
+}
+
+// Snapshot returns a copy of the current game state
+func (g *Game) Snapshot() Snapshot {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	snapshot := Snapshot{
+		State:        g.state,
+		Mode:         g.options.Mode,
+		LineTarget:   g.options.LineTarget,
+		Elapsed:      g.elapsed(),
+		TimeLimit:    g.options.TimeLimit,
+		Board:        make([][]Block, len(g.board.cells)),
+		AudioPlaying: g.isAudioPlaying(),
+	}
+
+	for x, column := range g.board.cells {
+		snapshot.Board[x] = copyBlocks(column)
+	}
+
+	if g.Player != nil {
+		snapshot.X = g.Player.X
+		snapshot.Y = g.Player.Y
+		snapshot.GhostX, snapshot.GhostY = g.ghostPosition()
+		snapshot.Shape = copyBlocks(g.Player.GetShapeBlocks())
+		for _, blocks := range g.Player.GetQueueBlocks() {
+			snapshot.Queue = append(snapshot.Queue, copyBlocks(blocks))
+		}
+		snapshot.HeldShape = copyBlocks(g.Player.GetHeldShapeBlocks())
+		snapshot.Score = g.Player.Score
+		snapshot.Level = g.Player.Level
+		snapshot.TotalRows = g.Player.TotalRows
+		snapshot.Combo = g.Player.Combo
+		snapshot.BackToBack = g.Player.BackToBack
+		snapshot.LastLock = g.lastLock
+	}
+
+	return snapshot
+}
+
+func copyBlocks(blocks []*Block) []Block {
+	if blocks == nil {
+		return nil
+	}
+	copied := make([]Block, len(blocks))
+	for i, block := range blocks {
+		copied[i] = *block
+	}
+	return copied
+}
+
+// restore copies the state of another game so this game plays on from
+// the same point. The board, shapes and randomizer are copied so the
+// games play on independently, audio and listeners are left alone
+func (g *Game) restore(other *Game) {
+	g.options = other.options
+	g.state = other.state
+	g.prevState = other.prevState
+	g.board = other.board.copy()
+	g.seed = other.seed
+
+	player := *other.Player
+	player.shape = other.Player.shape.copy()
+	player.queue = make([]*Shape, len(other.Player.queue), other.Player.previews)
+	for i, shape := range other.Player.queue {
+		player.queue[i] = shape.copy()
+	}
+	player.heldShape = other.Player.heldShape.copy()
+	player.randomizer = dealtRandomizer(other.options.Randomizer, other.Player.randomizer.Seed(), other.Player.dealt)
+	g.Player = &player
+
+	g.frame = other.frame
+	g.frameTime = other.frameTime
+	g.gravity = other.gravity
+	g.softDrop = other.softDrop
+	g.lockFrames = other.lockFrames
+	g.lockResets = other.lockResets
+	g.lowestY = other.lowestY
+	g.rotated = other.rotated
+	g.lastKick = other.lastKick
+
+	g.softDropRows = other.softDropRows
+	g.hardDropRows = other.hardDropRows
+	g.lastLock = other.lastLock
+	g.lastLock.Rows = append([]int(nil), other.lastLock.Rows...)
+
+	g.result = other.result
+	g.rank = other.rank
+	g.replay = other.replay
+	g.replay.Inputs = append([]ReplayInput(nil), other.replay.Inputs...)
+	g.dirty = true
+}
+
+// clone returns a new game with a copy of the state of this game
+func (g *Game) clone() *Game {
+	c := NewGameWithOptions(g.options)
+	c.restore(g)
+	return c
+}
@@ -0,0 +1,330 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// SavedShape is a shape as it is stored on disk
+type SavedShape struct {
+	Type    ShapeType   `json:"type"`
+	Colour  BlockColour `json:"colour"`
+	View    int         `json:"view"`
+	Visible bool        `json:"visible"`
+}
+
+// SavedGame is the full state of a game in progress as it is stored on disk,
+// so the game can carry on after the app is killed.
+// The recording holds the options and seed of the game,
+// the randomizer is restored by dealing the same number of shapes again
+type SavedGame struct {
+	Version int    `json:"version"`
+	Replay  []byte `json:"replay"`
+
+	Board [][]BlockColour `json:"board"`
+
+	Score         int           `json:"score"`
+	Level         int           `json:"level"`
+	Rows          int           `json:"rows"`
+	Combo         int           `json:"combo"`
+	MaxCombo      int           `json:"maxCombo"`
+	BackToBack    int           `json:"backToBack"`
+	MaxBackToBack int           `json:"maxBackToBack"`
+	PlayerState   PlayerState   `json:"playerState"`
+	X             int           `json:"x"`
+	Y             int           `json:"y"`
+	Shape         *SavedShape   `json:"shape"`
+	Queue         []*SavedShape `json:"queue"`
+	Held          *SavedShape   `json:"held"`
+	CanHold       bool          `json:"canHold"`
+	Dealt         int           `json:"dealt"`
+	SpawnX        int           `json:"spawnX"`
+	SpawnY        int           `json:"spawnY"`
+
+	Frame        int           `json:"frame"`
+	FrameTime    time.Duration `json:"frameTime"`
+	Gravity      int           `json:"gravity"`
+	SoftDrop     bool          `json:"softDrop"`
+	LockFrames   int           `json:"lockFrames"`
+	LockResets   int           `json:"lockResets"`
+	LowestY      int           `json:"lowestY"`
+	Rotated      bool          `json:"rotated"`
+	LastKick     int           `json:"lastKick"`
+	SoftDropRows int           `json:"softDropRows"`
+	HardDropRows int           `json:"hardDropRows"`
+	LastLock     LockResult    `json:"lastLock"`
+}
+
+// SetStatePath sets the file a game in progress is saved to when it is suspended
+func (g *Game) SetStatePath(path string) {
+	g.mutex.Lock()
+	defer g.unlock()
+	g.statePath = path
+}
+
+// SaveState saves the game in progress to the state file,
+// when no game is in progress any saved game is removed
+func (g *Game) SaveState() error {
+	g.mutex.Lock()
+	defer g.unlock()
+	return g.saveState()
+}
+
+func (g *Game) saveState() error {
+	if g.statePath == "" {
+		return nil
+	}
+	if !g.inProgress() {
+		if err := os.Remove(g.statePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	saved, err := g.saved()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return writeFile(g.statePath, data)
+}
+
+// RestoreState restores the game saved to the state file, suspended ready to resume.
+// A missing file leaves the game as it is
+func (g *Game) RestoreState() error {
+	g.mutex.Lock()
+	defer g.unlock()
+
+	if g.statePath == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(g.statePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var saved SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("corrupt saved game %s: %s", g.statePath, err)
+	}
+	if err := saved.restore(g); err != nil {
+		return fmt.Errorf("corrupt saved game %s: %s", g.statePath, err)
+	}
+	return nil
+}
+
+// inProgress returns true while a game is being played or suspended part way through
+func (g *Game) inProgress() bool {
+	return g.state == Playing || (g.state == Suspended && g.prevState == Playing)
+}
+
+// saved returns the state of the game as it is stored on disk
+func (g *Game) saved() (SavedGame, error) {
+	replay, err := g.recording().MarshalBinary()
+	if err != nil {
+		return SavedGame{}, err
+	}
+
+	board := make([][]BlockColour, len(g.board.cells))
+	for x, column := range g.board.cells {
+		board[x] = make([]BlockColour, len(column))
+		for y, block := range column {
+			board[x][y] = block.Colour
+		}
+	}
+
+	player := g.Player
+	saved := SavedGame{
+		Version: StateVersion,
+		Replay:  replay,
+		Board:   board,
+
+		Score:         player.Score,
+		Level:         player.Level,
+		Rows:          player.TotalRows,
+		Combo:         player.Combo,
+		MaxCombo:      player.MaxCombo,
+		BackToBack:    player.BackToBack,
+		MaxBackToBack: player.MaxBackToBack,
+		PlayerState:   player.state,
+		X:             player.X,
+		Y:             player.Y,
+		Shape:         savedShape(player.shape),
+		Queue:         make([]*SavedShape, len(player.queue)),
+		Held:          savedShape(player.heldShape),
+		CanHold:       player.canHold,
+		Dealt:         player.dealt,
+		SpawnX:        player.spawnX,
+		SpawnY:        player.spawnY,
+
+		Frame:        g.frame,
+		FrameTime:    g.frameTime,
+		Gravity:      g.gravity,
+		SoftDrop:     g.softDrop,
+		LockFrames:   g.lockFrames,
+		LockResets:   g.lockResets,
+		LowestY:      g.lowestY,
+		Rotated:      g.rotated,
+		LastKick:     g.lastKick,
+		SoftDropRows: g.softDropRows,
+		HardDropRows: g.hardDropRows,
+		LastLock:     g.lastLock,
+	}
+	for i, shape := range player.queue {
+		saved.Queue[i] = savedShape(shape)
+	}
+	return saved, nil
+}
+
+// restore replaces the state of a game with the saved game,
+// leaving it suspended part way through. The game is left as it was if the saved game is invalid
+func (s SavedGame) restore(g *Game) error {
+	if s.Version != StateVersion {
+		return fmt.Errorf("unsupported saved game version %d", s.Version)
+	}
+
+	var replay Replay
+	if err := replay.UnmarshalBinary(s.Replay); err != nil {
+		return err
+	}
+	size := replay.BoardSize
+	if len(s.Board) != size.Width {
+		return fmt.Errorf("unexpected board width: %d", len(s.Board))
+	}
+	board := NewBoardWithSize(size)
+	for x, column := range s.Board {
+		if len(column) != size.Height {
+			return fmt.Errorf("unexpected board height: %d", len(column))
+		}
+		for y, colour := range column {
+			if colour < Empty || colour > Grey {
+				return fmt.Errorf("unexpected block colour: %d", colour)
+			}
+			board.cells[x][y].Colour = colour
+		}
+	}
+
+	if s.Shape == nil || len(s.Queue) != replay.Previews {
+		return fmt.Errorf("missing shapes")
+	}
+	shape, err := s.Shape.shape()
+	if err != nil {
+		return err
+	}
+	queue := make([]*Shape, len(s.Queue))
+	for i, saved := range s.Queue {
+		if saved == nil {
+			return fmt.Errorf("missing shapes")
+		}
+		if queue[i], err = saved.shape(); err != nil {
+			return err
+		}
+	}
+	held, err := s.Held.shape()
+	if err != nil {
+		return err
+	}
+	if s.Dealt < 0 || s.Frame < 0 {
+		return fmt.Errorf("unexpected %d shapes dealt at frame %d", s.Dealt, s.Frame)
+	}
+	if s.SpawnX < 0 || s.SpawnX >= size.Width || s.SpawnY < 0 || s.SpawnY >= size.Height {
+		return fmt.Errorf("unexpected spawn position: %d,%d", s.SpawnX, s.SpawnY)
+	}
+
+	options := replay.options()
+	player := &Player{
+		Score:         s.Score,
+		Level:         s.Level,
+		TotalRows:     s.Rows,
+		Combo:         s.Combo,
+		MaxCombo:      s.MaxCombo,
+		BackToBack:    s.BackToBack,
+		MaxBackToBack: s.MaxBackToBack,
+		state:         s.PlayerState,
+		X:             s.X,
+		Y:             s.Y,
+		shape:         shape,
+		queue:         queue,
+		previews:      len(queue),
+		heldShape:     held,
+		canHold:       s.CanHold,
+		randomizer:    dealtRandomizer(options.Randomizer, replay.Seed, s.Dealt),
+		dealt:         s.Dealt,
+		spawnX:        s.SpawnX,
+		spawnY:        s.SpawnY,
+	}
+	// the shape in play must be on the board clear of the stack
+	if !board.canPlayerFitAt(player, s.X, s.Y) {
+		return fmt.Errorf("unexpected shape position: %d,%d", s.X, s.Y)
+	}
+
+	g.stop()
+	g.options = options
+	g.board = board
+	g.Player = player
+
+	g.frame = s.Frame
+	g.frameTime = s.FrameTime
+	g.gravity = s.Gravity
+	g.softDrop = s.SoftDrop
+	g.lockFrames = s.LockFrames
+	g.lockResets = s.LockResets
+	g.lowestY = s.LowestY
+	g.rotated = s.Rotated
+	g.lastKick = s.LastKick
+
+	g.softDropRows = s.SoftDropRows
+	g.hardDropRows = s.HardDropRows
+	g.lastLock = s.LastLock
+
+	g.result = GameResult{}
+	g.rank = 0
+	replay.Frames = 0
+	g.replay = replay
+
+	g.setState(Suspended)
+	g.prevState = Playing
+	g.dirty = true
+	return nil
+}
+
+func savedShape(shape *Shape) *SavedShape {
+	if shape == nil {
+		return nil
+	}
+	return &SavedShape{
+		Type:    shape.shapeType,
+		Colour:  shape.views[0][0].Colour,
+		View:    shape.viewIndex,
+		Visible: shape.visible,
+	}
+}
+
+// shape returns the saved shape, a missing shape is nil
+func (s *SavedShape) shape() (*Shape, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.Type < Square || s.Type > T {
+		return nil, fmt.Errorf("unexpected shape type: %d", s.Type)
+	}
+	if s.Colour <= Empty || s.Colour >= Grey {
+		return nil, fmt.Errorf("unexpected shape colour: %d", s.Colour)
+	}
+	shape := NewShape(s.Type, s.Colour)
+	if s.View < 0 || s.View >= len(shape.views) {
+		return nil, fmt.Errorf("unexpected shape view: %d", s.View)
+	}
+	shape.viewIndex = s.View
+	shape.visible = s.Visible
+	return shape, nil
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// resumed carries on a suspended game without the real time driver
+func resumed(game *Game) *Game {
+	game.mutex.Lock()
+	defer game.unlock()
+	game.changeState(game.prevState)
+	return game
+}
+
+func TestRestoredGameCarriesOn(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), StateFile)
+	for _, mode := range []GameMode{MarathonMode, UltraMode} {
+		game := newTestGame(ModeOptions(mode), 24)
+		game.SetStatePath(path)
+		playRandomGame(game, 40)
+		if game.GetState() != Playing {
+			t.Fatalf("Expected %d game to still be playing", mode)
+		}
+
+		// the app is killed while suspended
+		game.SuspendGame()
+		restored := NewGame()
+		restored.SetStatePath(path)
+		if err := restored.RestoreState(); err != nil {
+			t.Fatal(err)
+		}
+		if restored.GetState() != Suspended || restored.GetPreviousState() != Playing {
+			t.Errorf("Expected restored game to be suspended from playing received: %d from: %d",
+				restored.GetState(), restored.GetPreviousState())
+		}
+		if restored.Mode() != mode || restored.Frame() != game.Frame() || !sameBoard(restored, game) {
+			t.Errorf("Expected restored %d game at frame: %d received %d game at frame: %d",
+				mode, game.Frame(), restored.Mode(), restored.Frame())
+		}
+
+		// both games play on identically
+		playRandomGame(resumed(game), 300)
+		playRandomGame(resumed(restored), 300)
+		if !sameBoard(restored, game) || restored.Player.Score != game.Player.Score ||
+			restored.Frame() != game.Frame() || restored.GetState() != game.GetState() {
+			t.Errorf("Expected restored game score: %d frame: %d received score: %d frame: %d",
+				game.Player.Score, game.Frame(), restored.Player.Score, restored.Frame())
+		}
+		if !reflect.DeepEqual(restored.Replay(), game.Replay()) {
+			t.Error("Expected restored game to carry on the recording")
+		}
+	}
+}
+
+func TestSaveStateRemovesFinishedGames(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), StateFile)
+	game := newTestGame(DefaultGameOptions(), 24)
+	game.SetStatePath(path)
+	game.SuspendGame()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected game to be saved: %s", err)
+	}
+
+	resumed(game).GameOver()
+	if err := game.SaveState(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected saved game to be removed received: %v", err)
+	}
+
+	restored := NewGame()
+	restored.SetStatePath(path)
+	if err := restored.RestoreState(); err != nil || restored.GetState() != Menu {
+		t.Errorf("Expected no game to restore received state: %d %v", restored.GetState(), err)
+	}
+}
+
+func TestRestoreStateRejectsCorruptFiles(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), StateFile)
+	game := newTestGame(DefaultGameOptions(), 24)
+	game.SetStatePath(path)
+	game.SuspendGame()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// moved returns the saved game with a position changed
+	moved := func(field string, value int) []byte {
+		var saved map[string]interface{}
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatal(err)
+		}
+		saved[field] = value
+		edited, err := json.Marshal(saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return edited
+	}
+
+	corrupt := map[string][]byte{
+		"truncated":             data[:len(data)/2],
+		"version":               []byte(`{"version":0}`),
+		"replay":                []byte(fmt.Sprintf(`{"version":%d,"replay":"SlVOSw=="}`, StateVersion)),
+		"shape off the board":   moved("x", 100),
+		"shape below the board": moved("y", -5),
+		"shape in the floor":    moved("y", 0),
+		"spawn off the board":   moved("spawnX", 100),
+	}
+	for name, content := range corrupt {
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		restored := NewGame()
+		restored.SetStatePath(path)
+		if err := restored.RestoreState(); err == nil {
+			t.Errorf("Expected an error restoring a %s saved game", name)
+		}
+		if restored.GetState() != Menu {
+			t.Errorf("Expected a %s saved game to leave the game in the menu received: %d", name, restored.GetState())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+// tSpinCorner is a corner of the 3x3 box around a T shape's centre block
+type tSpinCorner struct {
+	X, Y int
+}
+
+// tSpinFrontCorners are the two corners either side of the
+// point of the T for each rotation state, the other two are at the back
+var tSpinFrontCorners = map[RotationState][2]tSpinCorner{
+	Rotation0: {{0, 2}, {2, 2}},
+	RotationR: {{2, 0}, {2, 2}},
+	Rotation2: {{0, 0}, {2, 0}},
+	RotationL: {{0, 0}, {0, 2}},
+}
+
+// tSpinBackCorners are the corners behind the point of the T
+var tSpinBackCorners = map[RotationState][2]tSpinCorner{
+	Rotation0: {{0, 0}, {2, 0}},
+	RotationR: {{0, 0}, {0, 2}},
+	Rotation2: {{0, 2}, {2, 2}},
+	RotationL: {{2, 0}, {2, 2}},
+}
+
+// tSpinUpgradeKick is the index of the kick that turns
+// a mini T-spin into a full one, the last kick in the SRS tables
+const tSpinUpgradeKick = 4
+
+// tSpin uses the 3-corner rule to detect a T-spin for the player's shape
+// where it is about to lock, a T shape that last rotated into place
+// with three of the four corners around its centre filled is a T-spin,
+// it is a full T-spin if both corners at its point are filled
+func (g *Game) tSpin() TSpin {
+	if !g.rotated || g.Player.shape.Type() != T {
+		return NoTSpin
+	}
+
+	rotation := g.Player.shape.Rotation()
+	front := 0
+	for _, corner := range tSpinFrontCorners[rotation] {
+		if g.board.isFilled(g.Player.X+corner.X, g.Player.Y+corner.Y) {
+			front++
+		}
+	}
+	back := 0
+	for _, corner := range tSpinBackCorners[rotation] {
+		if g.board.isFilled(g.Player.X+corner.X, g.Player.Y+corner.Y) {
+			back++
+		}
+	}
+
+	switch {
+	case front+back < 3:
+		return NoTSpin
+	case front == 2 || g.lastKick == tSpinUpgradeKick:
+		return FullTSpin
+	default:
+		return MiniTSpin
+	}
+}
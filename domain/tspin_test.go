@@ -0,0 +1,89 @@
+package domain
+
+import "testing"
+
+// setupTSlot builds a slot for a T shape pointing down at x 3 on the bottom row,
+// with the shape turned right above it ready to rotate in
+func setupTSlot(game *Game, miniSlot bool) {
+	if miniSlot {
+		fillRow(&game.board, 1, 4, 5)
+		game.board.cells[5][3].Colour = Red
+	} else {
+		fillRow(&game.board, 1, 4)
+	}
+	fillRow(&game.board, 2, 3, 4, 5)
+	game.board.cells[3][3].Colour = Red
+
+	game.Player.shape = TShape(Purple)
+	game.Player.shape.RotateBack() // turned right
+	game.Player.X = 3
+	game.Player.Y = 1
+}
+
+func TestTSpins(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		mini     bool
+		move     bool
+		expected TSpin
+		rows     int
+	}{
+		{name: "full", expected: FullTSpin, rows: 2},
+		{name: "mini", mini: true, expected: MiniTSpin, rows: 1},
+		{name: "moved after rotating", move: true, expected: NoTSpin, rows: 2},
+	}
+
+	for _, test := range tests {
+		game := newTestGame(DefaultGameOptions(), 13)
+		setupTSlot(game, test.mini)
+
+		if test.move {
+			// rotate above the slot and drop into it
+			game.board.cells[3][3].Colour = Empty
+			game.Player.Y = 2
+			if !game.Rotate() || !game.MoveDown() {
+				t.Errorf("%s Expected the shape to drop into the slot", test.name)
+			}
+			game.board.cells[3][3].Colour = Red
+		} else if !game.Rotate() {
+			t.Errorf("%s Expected the shape to rotate into the slot", test.name)
+		}
+
+		spins := 0
+		unsubscribe := game.Subscribe(EventListenerFunc(func(event Event) {
+			if event.Type == TSpinEvent {
+				spins++
+			}
+		}))
+		game.HardDrop()
+		unsubscribe()
+
+		lock := game.lastLock
+		if lock.TSpin != test.expected {
+			t.Errorf("%s Expected T-spin: %d received: %d", test.name, test.expected, lock.TSpin)
+		}
+		if len(lock.Rows) != test.rows {
+			t.Errorf("%s Expected rows: %d received: %d", test.name, test.rows, len(lock.Rows))
+		}
+		if test.expected != NoTSpin && spins != 1 {
+			t.Errorf("%s Expected a T-spin event received: %d", test.name, spins)
+		}
+		if test.expected != NoTSpin && !lock.Difficult() {
+			t.Errorf("%s Expected T-spin clear to be difficult", test.name)
+		}
+	}
+}
+
+func TestGuidelineScorerTSpins(t *testing.T) {
+
+	double := GuidelineScorer.Score(LockResult{Level: 2, TSpin: FullTSpin, Rows: []int{1, 2}})
+	if double.Rows != 2400 {
+		t.Errorf("Expected T-spin double score: %d received: %d", 2400, double.Rows)
+	}
+
+	mini := GuidelineScorer.Score(LockResult{Level: 1, TSpin: MiniTSpin})
+	if mini.Rows != 100 {
+		t.Errorf("Expected mini T-spin score: %d received: %d", 100, mini.Rows)
+	}
+}
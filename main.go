@@ -3,9 +3,15 @@
 package main
 
 import (
+	"log"
+	"os"
+	"path/filepath"
+
 	"github.com/telecoda/go-teletris/domain"
 	"github.com/telecoda/go-teletris/scene"
 	"github.com/telecoda/gomo-simra/simra"
+	"golang.org/x/mobile/app"
+	"golang.org/x/mobile/event/key"
 )
 
 var game *domain.Game
@@ -18,7 +24,19 @@ func main() {
 	engine := simra.GetInstance()
 
 	game = domain.NewGame()
+	game.SetHighScoresPath(filepath.Join(dataDir(), domain.HighScoresFile))
+	game.SetReplayPath(filepath.Join(dataDir(), domain.ReplayFile))
+	if err := game.LoadHighScores(); err != nil {
+		log.Printf("Error loading high scores: %s", err)
+	}
+	// carry on with a game the app was killed part way through
+	game.SetStatePath(filepath.Join(dataDir(), domain.StateFile))
+	if err := game.RestoreState(); err != nil {
+		log.Printf("Error restoring game: %s", err)
+	}
 	initScenes()
+	// simra only passes touches on to scenes, keys are picked off before it sees them
+	app.RegisterFilter(filterKeys)
 
 	onStart := make(chan bool)
 	onStop := make(chan bool)
@@ -26,6 +44,28 @@ func main() {
 	engine.Start(onStart, onStop)
 }
 
+// dataDir returns a directory the app can keep its files in
+func dataDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+	// android apps have no home, gomobile points TMPDIR at the app's cache dir
+	return filepath.Dir(os.TempDir())
+}
+
+// filterKeys passes keys pressed on desktop to the scene shown,
+// every other event is left for simra
+func filterKeys(event interface{}) interface{} {
+	e, ok := event.(key.Event)
+	if !ok {
+		return event
+	}
+	if e.Direction == key.DirPress {
+		scene.OnKeyDown(e.Code)
+	}
+	return nil
+}
+
 func initScenes() {
 	if titleScene == nil {
 		titleScene = &scene.TitleScene{Game: game}
@@ -47,7 +87,7 @@ func eventHandle(onStart, onStop chan bool) {
 			//titleScene = nil
 			//levelScene = nil
 			//suspendScene = nil
-			// stop the music!
+			// stop the music and save the game in progress
 			game.SuspendGame()
 		}
 	}
@@ -63,7 +103,7 @@ func setScene(engine *simra.Simra) {
 	case domain.Suspended:
 		// use previous scene again
 		setPreviousScene(engine)
-	case domain.GameOver:
+	case domain.GameOver, domain.Finished:
 		engine.SetScene(levelScene)
 	}
 }
@@ -77,7 +117,7 @@ func setPreviousScene(engine *simra.Simra) {
 	case domain.Suspended:
 		// do nothing..
 		break
-	case domain.GameOver:
+	case domain.GameOver, domain.Finished:
 		engine.SetScene(levelScene)
 	}
 }
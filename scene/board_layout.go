@@ -0,0 +1,72 @@
+package scene
+
+import (
+	"image"
+
+	"github.com/telecoda/go-teletris/domain"
+	"github.com/telecoda/go-teletris/scene/config"
+)
+
+// boardLayout positions a board of any size on screen,
+// blocks shrink when the board is too big to fit at full size
+type boardLayout struct {
+	blockPixels int
+	offsetX     int
+	offsetY     int
+}
+
+// newBoardLayout fits a board into the space the standard board fills,
+// between the info panel at the top and the bottom of the screen
+func newBoardLayout(size domain.BoardSize) boardLayout {
+	maxWide := config.ScreenWidth - domain.BoardOffsetX
+	maxHigh := domain.StandardBoard.Height * domain.BlockPixels
+
+	blockPixels := domain.BlockPixels
+	if size.Width > 0 && maxWide/size.Width < blockPixels {
+		blockPixels = maxWide / size.Width
+	}
+	if size.Height > 0 && maxHigh/size.Height < blockPixels {
+		blockPixels = maxHigh / size.Height
+	}
+
+	// centre the board where the standard board is drawn
+	standardWide := domain.StandardBoard.Width * domain.BlockPixels
+	return boardLayout{
+		blockPixels: blockPixels,
+		offsetX:     domain.BoardOffsetX + (standardWide-size.Width*blockPixels)/2,
+		offsetY:     domain.BoardOffsetY,
+	}
+}
+
+// spriteX returns the screen x of the centre of a block in board column x
+func (b boardLayout) spriteX(x int) float32 {
+	return float32(b.blockPixels*x + b.blockPixels/2 + b.offsetX)
+}
+
+// spriteY returns the screen y of the centre of a block in board row y
+func (b boardLayout) spriteY(y int) float32 {
+	return float32(b.blockPixels*y + b.blockPixels/2 + b.offsetY)
+}
+
+// imageRect returns the rectangle covered by a board cell
+// in an image of the given height, image y coordinates run top down
+func (b boardLayout) imageRect(x, y, imageHeight int) image.Rectangle {
+	xCoord := x*b.blockPixels + b.offsetX
+	yCoord := imageHeight - (y+1)*b.blockPixels - b.offsetY
+	return image.Rect(xCoord, yCoord, xCoord+b.blockPixels, yCoord+b.blockPixels)
+}
+
+// scaleImage resizes a square block image for drawing onto the background
+func scaleImage(source *image.RGBA, pixels int) *image.RGBA {
+	bounds := source.Bounds()
+	if bounds.Dx() == pixels && bounds.Dy() == pixels {
+		return source
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, pixels, pixels))
+	for y := 0; y < pixels; y++ {
+		for x := 0; x < pixels; x++ {
+			scaled.Set(x, y, source.At(bounds.Min.X+x*bounds.Dx()/pixels, bounds.Min.Y+y*bounds.Dy()/pixels))
+		}
+	}
+	return scaled
+}
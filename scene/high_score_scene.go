@@ -0,0 +1,478 @@
+package scene
+
+import (
+	"image"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/telecoda/go-teletris/domain"
+	"github.com/telecoda/go-teletris/scene/config"
+	"github.com/telecoda/gomo-simra/simra"
+	"github.com/telecoda/gomo-simra/simra/peer"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/exp/sprite"
+)
+
+// letters in the order they appear in letters.png,
+// the dash marks results that haven't been named
+const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ-"
+
+const dashLetter = len(letters) - 1
+
+// rankedLeaderboards are the high score tables of the games offered in the menu
+func rankedLeaderboards() []domain.LeaderboardKey {
+	keys := make([]domain.LeaderboardKey, 0, len(modeButtons))
+	for _, button := range modeButtons {
+		if options := button.options(); options.Ranked {
+			keys = append(keys, options.LeaderboardKey())
+		}
+	}
+	return keys
+}
+
+// highScoreRow holds the sprites for one result in the table
+type highScoreRow struct {
+	rankDigits  []*simra.Sprite
+	nameLetters []*simra.Sprite
+	valueDigits []*simra.Sprite
+	separators  []*simra.Sprite
+}
+
+func (r highScoreRow) sprites() []*simra.Sprite {
+	sprites := make([]*simra.Sprite, 0)
+	sprites = append(sprites, r.rankDigits...)
+	sprites = append(sprites, r.nameLetters...)
+	sprites = append(sprites, r.valueDigits...)
+	return append(sprites, r.separators...)
+}
+
+// HighScoreScene shows the high score table of a game mode.
+// When Entry is set the player picks initials for the result of the last game
+type HighScoreScene struct {
+	sync.Mutex
+	Game        *domain.Game
+	Leaderboard domain.LeaderboardKey
+	Entry       bool
+
+	background     *simra.Sprite
+	modeLabel      *simra.Sprite
+	leftButton     *simra.Sprite
+	rightButton    *simra.Sprite
+	okButton       *simra.Sprite
+	rows           []highScoreRow
+	digitTextures  map[int]*sprite.SubTex
+	letterTextures map[int]*sprite.SubTex
+
+	// initials being picked
+	rank   int
+	name   []byte
+	cursor int
+	frame  int
+}
+
+// Initialize initializes HighScoreScene
+func (h *HighScoreScene) Initialize() {
+	simra.GetInstance().SetDesiredScreenSize(config.ScreenWidth, config.ScreenHeight)
+
+	if h.Entry {
+		h.rank = h.Game.ResultRank()
+		h.name = []byte(strings.Repeat("A", domain.NameLength))
+		h.cursor = 0
+	}
+
+	// initialize sprites
+	h.initialize()
+	listenForKeys(h)
+}
+
+func (h *HighScoreScene) initialize() {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.digitTextures = sliceTextures("digits.png", 10, domain.DigitsWidth, domain.DigitsHeight)
+	h.letterTextures = sliceTextures("letters.png", len(letters), domain.LettersWidth, domain.LettersHeight)
+	h.initBackground()
+	h.initButtons()
+	h.initModeLabel()
+	h.initRows()
+}
+
+func (h *HighScoreScene) Destroy() {
+	stopListeningForKeys(h)
+	go h.destroy()
+}
+
+func (h *HighScoreScene) destroy() {
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	h.background = nil
+	h.modeLabel = nil
+	for _, button := range []*simra.Sprite{h.leftButton, h.rightButton, h.okButton} {
+		if button != nil {
+			button.RemoveAllTouchListener()
+		}
+	}
+	h.leftButton = nil
+	h.rightButton = nil
+	h.okButton = nil
+	for _, row := range h.rows {
+		for _, letter := range row.nameLetters {
+			letter.RemoveAllTouchListener()
+		}
+	}
+	h.rows = nil
+	for n, _ := range h.digitTextures {
+		h.digitTextures[n] = nil
+	}
+	for n, _ := range h.letterTextures {
+		h.letterTextures[n] = nil
+	}
+	runtime.GC()
+}
+
+func (h *HighScoreScene) initBackground() {
+	// add background sprite
+	h.background = &simra.Sprite{}
+	h.background.W = float32(config.ScreenWidth)
+	h.background.H = float32(config.ScreenHeight)
+
+	// put center of screen
+	h.background.X = config.ScreenWidth / 2
+	h.background.Y = config.ScreenHeight / 2
+
+	simra.GetInstance().AddSprite("background.png",
+		image.Rect(0, 0, int(h.background.W), int(h.background.H)),
+		h.background)
+}
+
+// initButtons adds the buttons along the bottom of the screen,
+// the arrows pick letters during entry and switch modes otherwise
+func (h *HighScoreScene) initButtons() {
+
+	centreX := config.ScreenWidth / 2
+
+	h.leftButton = h.initButton("left.png", 27, centreX-domain.MenuButtonSpacing)
+	h.leftButton.AddTouchListener(&highScoreTouchListener{onTouch: func() { h.step(-1) }})
+
+	h.okButton = h.initButton("ok.png", 60, centreX)
+	h.okButton.AddTouchListener(&highScoreTouchListener{onTouch: h.ok})
+
+	h.rightButton = h.initButton("right.png", 27, centreX+domain.MenuButtonSpacing)
+	h.rightButton.AddTouchListener(&highScoreTouchListener{onTouch: func() { h.step(1) }})
+}
+
+func (h *HighScoreScene) initButton(name string, width int, x int) *simra.Sprite {
+	button := &simra.Sprite{}
+
+	// scale up the buttons so they are easy to press
+	button.W = float32(width * 3 / 2)
+	button.H = float32(domain.MenuButtonHeight * 3 / 2)
+
+	button.X = float32(x)
+	button.Y = float32(domain.HighScoreButtonsY)
+
+	simra.GetInstance().AddSprite(name,
+		image.Rect(0, 0, width, domain.MenuButtonHeight),
+		button)
+	return button
+}
+
+// initModeLabel shows which mode the table is for along the top of the screen
+func (h *HighScoreScene) initModeLabel() {
+
+	for _, button := range modeButtons {
+		if button.mode != h.Leaderboard.Mode || button.options().TimeLimit != h.Leaderboard.TimeLimit {
+			continue
+		}
+		h.modeLabel = &simra.Sprite{}
+		h.modeLabel.W = float32(button.width)
+		h.modeLabel.H = float32(domain.MenuButtonHeight)
+
+		h.modeLabel.X = config.ScreenWidth / 2
+		h.modeLabel.Y = float32(config.ScreenHeight - domain.HighScoreOffsetY/2)
+
+		simra.GetInstance().AddSprite(button.image,
+			image.Rect(0, 0, button.width, domain.MenuButtonHeight),
+			h.modeLabel)
+	}
+}
+
+// initRows adds a row of sprites for each result on the leaderboard
+func (h *HighScoreScene) initRows() {
+
+	leaderboard := h.Game.Leaderboard(h.Leaderboard)
+
+	// centre rows of rank, initials and score or time
+	digitWidth := domain.BlockPixels / 2
+	rowWidth := (domain.MaxRankDigits+domain.NameLength+6+2)*digitWidth + 2*domain.TimerSeparatorWidth
+	startX := (config.ScreenWidth-rowWidth)/2 + digitWidth/2
+
+	h.rows = make([]highScoreRow, len(leaderboard.Results))
+	for i, result := range leaderboard.Results {
+		y := float32(config.ScreenHeight - domain.HighScoreOffsetY - i*domain.HighScoreRowSpacing)
+		x := float32(startX)
+		row := &h.rows[i]
+
+		for _, digit := range rankToDigits(i + 1) {
+			row.rankDigits = append(row.rankDigits, h.addCell("digits.png", x, y, h.digitTextures[digit]))
+			x += float32(digitWidth)
+		}
+		x += float32(digitWidth)
+
+		name := result.Name
+		if h.Entry && i+1 == h.rank {
+			name = string(h.name)
+		}
+		for n, letter := range nameToLetters(name) {
+			cell := h.addCell("letters.png", x, y, h.letterTextures[letter])
+			if h.Entry && i+1 == h.rank {
+				// tap a letter to pick it
+				cursor := n
+				cell.AddTouchListener(&highScoreTouchListener{onTouch: func() { h.moveCursor(cursor) }})
+			}
+			row.nameLetters = append(row.nameLetters, cell)
+			x += float32(digitWidth)
+		}
+		x += float32(digitWidth)
+
+		if h.Leaderboard.Mode != domain.SprintMode {
+			for _, digit := range scoreToDigits(result.Score) {
+				row.valueDigits = append(row.valueDigits, h.addCell("digits.png", x, y, h.digitTextures[digit]))
+				x += float32(digitWidth)
+			}
+			continue
+		}
+
+		// sprints are ranked by time
+		for n, digit := range timeToDigits(result.Elapsed) {
+			if n == 2 || n == 4 {
+				separator := "colon.png"
+				if n == 4 {
+					separator = "point.png"
+				}
+				cell := &simra.Sprite{}
+				cell.W = float32(domain.TimerSeparatorWidth)
+				cell.H = float32(domain.BlockPixels)
+				cell.X = x - float32(domain.TimerSeparatorWidth)
+				cell.Y = y
+				simra.GetInstance().AddSprite(separator,
+					image.Rect(0, 0, 15, 40),
+					cell)
+				row.separators = append(row.separators, cell)
+				x += float32(domain.TimerSeparatorWidth)
+			}
+			row.valueDigits = append(row.valueDigits, h.addCell("digits.png", x, y, h.digitTextures[digit]))
+			x += float32(digitWidth)
+		}
+	}
+}
+
+// addCell adds a digit or letter sprite and gives it its texture
+func (h *HighScoreScene) addCell(name string, x, y float32, tex *sprite.SubTex) *simra.Sprite {
+	cell := &simra.Sprite{}
+	cell.W = float32(domain.BlockPixels / 2)
+	cell.H = float32(domain.BlockPixels)
+	cell.X = x
+	cell.Y = y
+
+	simra.GetInstance().AddSprite(name,
+		image.Rect(0, 0, domain.DigitsWidth, domain.DigitsHeight),
+		cell)
+	// replace texture straightaway
+	peer.GetSpriteContainer().ReplaceTexture(&cell.Sprite, *tex)
+	return cell
+}
+
+func (h *HighScoreScene) removeRows() {
+	for _, row := range h.rows {
+		for _, cell := range row.sprites() {
+			cell.RemoveAllTouchListener()
+			simra.GetInstance().RemoveSprite(cell)
+		}
+	}
+	h.rows = nil
+}
+
+// entryRow returns the row initials are being picked for
+func (h *HighScoreScene) entryRow() *highScoreRow {
+	if !h.Entry || h.rank < 1 || h.rank > len(h.rows) {
+		return nil
+	}
+	return &h.rows[h.rank-1]
+}
+
+// step changes the picked letter or the leaderboard being shown
+func (h *HighScoreScene) step(direction int) {
+	if !h.Entry {
+		h.Leaderboard = stepLeaderboard(h.Leaderboard, direction)
+		if h.modeLabel != nil {
+			simra.GetInstance().RemoveSprite(h.modeLabel)
+			h.modeLabel = nil
+		}
+		h.removeRows()
+		h.initModeLabel()
+		h.initRows()
+		return
+	}
+	h.name[h.cursor] = stepLetter(h.name[h.cursor], direction)
+	h.updateName()
+}
+
+func (h *HighScoreScene) moveCursor(cursor int) {
+	if cursor < 0 || cursor >= len(h.name) {
+		return
+	}
+	h.cursor = cursor
+	h.updateName()
+}
+
+// ok moves on to the next letter, once all the initials are picked
+// they are saved with the score
+func (h *HighScoreScene) ok() {
+	if !h.Entry {
+		simra.GetInstance().SetScene(&ModeScene{Game: h.Game})
+		return
+	}
+	if h.cursor < len(h.name)-1 {
+		h.moveCursor(h.cursor + 1)
+		return
+	}
+	if err := h.Game.SetResultName(string(h.name)); err != nil {
+		log.Printf("Error saving high scores: %s", err)
+	}
+	h.Game.StartMenu()
+	simra.GetInstance().SetScene(&TitleScene{Game: h.Game})
+}
+
+// updateName shows the initials picked so far
+func (h *HighScoreScene) updateName() {
+	row := h.entryRow()
+	if row == nil {
+		return
+	}
+	for i, letter := range nameToLetters(string(h.name)) {
+		if i >= len(row.nameLetters) {
+			continue
+		}
+		peer.GetSpriteContainer().ReplaceTexture(&row.nameLetters[i].Sprite, *h.letterTextures[letter])
+		// show letters that may have been hidden by the cursor
+		row.nameLetters[i].W = float32(domain.BlockPixels / 2)
+	}
+}
+
+// OnKeyDown picks initials or switches modes on desktop
+func (h *HighScoreScene) OnKeyDown(code key.Code) {
+	switch code {
+	case key.CodeUpArrow:
+		if h.Entry {
+			h.step(1)
+		}
+	case key.CodeDownArrow:
+		if h.Entry {
+			h.step(-1)
+		}
+	case key.CodeLeftArrow:
+		if h.Entry {
+			h.moveCursor(h.cursor - 1)
+		} else {
+			h.step(-1)
+		}
+	case key.CodeRightArrow:
+		if h.Entry {
+			h.moveCursor(h.cursor + 1)
+		} else {
+			h.step(1)
+		}
+	case key.CodeReturnEnter, key.CodeSpacebar:
+		h.ok()
+	}
+}
+
+// Drive blinks the letter being picked
+func (h *HighScoreScene) Drive() {
+	row := h.entryRow()
+	if row == nil || h.cursor >= len(row.nameLetters) {
+		return
+	}
+	h.frame++
+	cursor := row.nameLetters[h.cursor]
+	if h.frame/domain.CursorBlinkFrames%2 == 0 {
+		cursor.W = float32(domain.BlockPixels / 2)
+	} else {
+		cursor.W = 0
+	}
+}
+
+// highScoreTouchListener calls a function when a sprite is tapped
+type highScoreTouchListener struct {
+	onTouch func()
+}
+
+func (t *highScoreTouchListener) OnTouchBegin(x, y float32) {
+}
+
+func (t *highScoreTouchListener) OnTouchMove(x, y float32) {
+}
+
+func (t *highScoreTouchListener) OnTouchEnd(x, y float32) {
+	t.onTouch()
+}
+
+// rankToDigits converts a rank to an array of digit image indexes
+func rankToDigits(rank int) []int {
+
+	numberDigits := numberToDigits(rank)
+
+	if len(numberDigits) >= domain.MaxRankDigits {
+		return numberDigits[len(numberDigits)-domain.MaxRankDigits:]
+	}
+
+	// if not right length, zero pad result
+	diff := domain.MaxRankDigits - len(numberDigits)
+	zeroDigits := make([]int, diff)
+	numberDigits = append(zeroDigits, numberDigits...)
+	return numberDigits
+}
+
+// nameToLetters converts initials to an array of letter image indexes,
+// missing or unknown letters are shown as dashes
+func nameToLetters(name string) []int {
+
+	nameLetters := make([]int, domain.NameLength)
+	for i := range nameLetters {
+		nameLetters[i] = dashLetter
+		if i >= len(name) {
+			continue
+		}
+		if index := strings.IndexByte(letters[:dashLetter], name[i]); index >= 0 {
+			nameLetters[i] = index
+		}
+	}
+	return nameLetters
+}
+
+// stepLetter returns the letter a number of steps from another, wrapping from Z to A
+func stepLetter(letter byte, steps int) byte {
+	index := strings.IndexByte(letters[:dashLetter], letter)
+	if index < 0 {
+		index = 0
+	}
+	index = ((index+steps)%dashLetter + dashLetter) % dashLetter
+	return letters[index]
+}
+
+// stepLeaderboard returns the ranked leaderboard a number of steps from another
+func stepLeaderboard(key domain.LeaderboardKey, steps int) domain.LeaderboardKey {
+	keys := rankedLeaderboards()
+	index := 0
+	for i, ranked := range keys {
+		if ranked == key {
+			index = i
+		}
+	}
+	count := len(keys)
+	return keys[((index+steps)%count+count)%count]
+}
@@ -0,0 +1,57 @@
+package scene
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/telecoda/go-teletris/domain"
+)
+
+func TestNameToLetters(t *testing.T) {
+
+	cases := map[string][]int{
+		"ABC":  {0, 1, 2},
+		"ZZ":   {25, 25, dashLetter},
+		"":     {dashLetter, dashLetter, dashLetter},
+		"a?Z":  {dashLetter, dashLetter, 25},
+		"ABCD": {0, 1, 2},
+	}
+
+	for name, expected := range cases {
+		letters := nameToLetters(name)
+		if !reflect.DeepEqual(letters, expected) {
+			t.Errorf("Letters for %q incorrect Expected: %d got: %d", name, expected, letters)
+		}
+	}
+}
+
+func TestStepLetterAndLeaderboard(t *testing.T) {
+
+	if letter := stepLetter('A', -1); letter != 'Z' {
+		t.Errorf("Expected letter: %c got: %c", 'Z', letter)
+	}
+	if letter := stepLetter('Z', 1); letter != 'A' {
+		t.Errorf("Expected letter: %c got: %c", 'A', letter)
+	}
+	if letter := stepLetter('C', 2); letter != 'E' {
+		t.Errorf("Expected letter: %c got: %c", 'E', letter)
+	}
+
+	marathon := domain.ModeOptions(domain.MarathonMode).LeaderboardKey()
+	ultra := domain.ModeOptions(domain.UltraMode).LeaderboardKey()
+	shortUltra := ultra
+	shortUltra.TimeLimit = domain.ShortUltraDuration
+	if key := stepLeaderboard(marathon, -1); key != shortUltra {
+		t.Errorf("Expected leaderboard: %+v got: %+v", shortUltra, key)
+	}
+	if key := stepLeaderboard(shortUltra, -1); key != ultra {
+		t.Errorf("Expected leaderboard: %+v got: %+v", ultra, key)
+	}
+	if key := stepLeaderboard(domain.ModeOptions(domain.ZenMode).LeaderboardKey(), 0); key != marathon {
+		t.Errorf("Expected unranked game to fall back to: %+v got: %+v", marathon, key)
+	}
+	custom := domain.LeaderboardKey{Mode: domain.SprintMode, LineTarget: 2}
+	if key := stepLeaderboard(custom, 0); key != marathon {
+		t.Errorf("Expected sprint of 2 rows to fall back to: %+v got: %+v", marathon, key)
+	}
+}
@@ -0,0 +1,45 @@
+package scene
+
+import (
+	"sync"
+
+	"golang.org/x/mobile/event/key"
+)
+
+// keyListener is a scene that handles keys pressed on desktop
+type keyListener interface {
+	OnKeyDown(code key.Code)
+}
+
+// keyScene is the scene shown that handles keys, if any.
+// simra only passes touches on to scenes so keys are passed on from main
+var keyScene struct {
+	sync.Mutex
+	listener keyListener
+}
+
+// listenForKeys passes keys pressed on desktop to a scene while it is shown
+func listenForKeys(listener keyListener) {
+	keyScene.Lock()
+	defer keyScene.Unlock()
+	keyScene.listener = listener
+}
+
+// stopListeningForKeys stops passing keys to a scene once it is destroyed
+func stopListeningForKeys(listener keyListener) {
+	keyScene.Lock()
+	defer keyScene.Unlock()
+	if keyScene.listener == listener {
+		keyScene.listener = nil
+	}
+}
+
+// OnKeyDown passes a key pressed on desktop to the scene shown
+func OnKeyDown(code key.Code) {
+	keyScene.Lock()
+	listener := keyScene.listener
+	keyScene.Unlock()
+	if listener != nil {
+		listener.OnKeyDown(code)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/telecoda/go-teletris/scene/io"
 	"github.com/telecoda/gomo-simra/simra"
 	"github.com/telecoda/gomo-simra/simra/peer"
+	"golang.org/x/mobile/event/key"
 	"golang.org/x/mobile/exp/sprite"
 )
 
@@ -33,14 +34,31 @@ type LevelScene struct {
 	scoreDigits      []*simra.Sprite
 	levelLabel       *simra.Sprite
 	levelDigits      []*simra.Sprite
+	timeLabel        *simra.Sprite
+	timerDigits      []*simra.Sprite
+	timerSeparators  []*simra.Sprite
+	linesLabel       *simra.Sprite
+	linesDigits      []*simra.Sprite
+	comboLabel       *simra.Sprite
+	comboDigits      []*simra.Sprite
+	backToBackLabel  *simra.Sprite
+	backToBackDigits []*simra.Sprite
 	audioSprite      *simra.Sprite
 	audioTextures    map[bool]*sprite.SubTex
 	gameOverLabel    *simra.Sprite
+	finishedLabel    *simra.Sprite
 	blockImages      map[domain.BlockColour]*image.RGBA
 	blockTextures    map[domain.BlockColour]*sprite.SubTex
+	ghostTextures    map[domain.BlockColour]*sprite.SubTex
 	digitTextures    map[int]*sprite.SubTex
 	playerSprites    []*simra.Sprite
+	ghostSprites     []*simra.Sprite
 	nextBlockSprites []*simra.Sprite
+	heldBlockSprites []*simra.Sprite
+
+	// game state being drawn
+	snapshot domain.Snapshot
+	layout   boardLayout
 
 	// images
 	backgroundImage image.Image
@@ -54,11 +72,15 @@ func (l *LevelScene) Initialize() {
 	simra.GetInstance().SetDesiredScreenSize(config.ScreenWidth, config.ScreenHeight)
 	l.Mutex.Lock()
 	defer l.Mutex.Unlock()
+	l.snapshot = l.Game.Snapshot()
+	l.layout = newBoardLayout(l.Game.BoardSize())
 	// initialize sprites
 	l.initSprites()
+	listenForKeys(l)
 }
 
 func (l *LevelScene) Destroy() {
+	stopListeningForKeys(l)
 	go l.destroy()
 }
 
@@ -71,8 +93,13 @@ func (l *LevelScene) destroy() {
 	l.background = nil
 	l.scoreLabel = nil
 	l.levelLabel = nil
+	l.timeLabel = nil
+	l.linesLabel = nil
+	l.comboLabel = nil
+	l.backToBackLabel = nil
 	l.audioSprite = nil
 	l.gameOverLabel = nil
+	l.finishedLabel = nil
 
 	for n, _ := range l.levelDigits {
 		l.levelDigits[n] = nil
@@ -80,6 +107,21 @@ func (l *LevelScene) destroy() {
 	for n, _ := range l.scoreDigits {
 		l.scoreDigits[n] = nil
 	}
+	for n, _ := range l.timerDigits {
+		l.timerDigits[n] = nil
+	}
+	for n, _ := range l.timerSeparators {
+		l.timerSeparators[n] = nil
+	}
+	for n, _ := range l.linesDigits {
+		l.linesDigits[n] = nil
+	}
+	for n, _ := range l.comboDigits {
+		l.comboDigits[n] = nil
+	}
+	for n, _ := range l.backToBackDigits {
+		l.backToBackDigits[n] = nil
+	}
 	for key, _ := range l.audioTextures {
 		l.audioTextures[key] = nil
 	}
@@ -89,12 +131,21 @@ func (l *LevelScene) destroy() {
 	for n, _ := range l.blockTextures {
 		l.blockTextures[n] = nil
 	}
+	for n, _ := range l.ghostTextures {
+		l.ghostTextures[n] = nil
+	}
 	for n, _ := range l.playerSprites {
 		l.playerSprites[n] = nil
 	}
+	for n, _ := range l.ghostSprites {
+		l.ghostSprites[n] = nil
+	}
 	for n, _ := range l.nextBlockSprites {
 		l.nextBlockSprites[n] = nil
 	}
+	for n, _ := range l.heldBlockSprites {
+		l.heldBlockSprites[n] = nil
+	}
 
 	runtime.GC()
 
@@ -134,6 +185,7 @@ func (l *LevelScene) initBlockTextures() {
 
 	l.blockTextures = make(map[domain.BlockColour]*sprite.SubTex, 0)
 	l.blockImages = make(map[domain.BlockColour]*image.RGBA, 0)
+	l.ghostTextures = make(map[domain.BlockColour]*sprite.SubTex, 0)
 	rect := image.Rect(0, 0, domain.BlockPixels, domain.BlockPixels)
 
 	for i, name := range domain.SpriteNames {
@@ -150,7 +202,14 @@ func (l *LevelScene) initBlockTextures() {
 			bounds := blockImage.Bounds()
 			blockRGBA := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
 			draw.Draw(blockRGBA, blockRGBA.Bounds(), blockImage, bounds.Min, draw.Src)
-			l.blockImages[i] = blockRGBA
+			l.blockImages[i] = scaleImage(blockRGBA, l.layout.blockPixels)
+
+			// Save faded texture for the ghost shape
+			ghostRGBA := image.NewRGBA(blockRGBA.Bounds())
+			ghostMask := image.NewUniform(color.Alpha{domain.GhostAlpha})
+			draw.DrawMask(ghostRGBA, ghostRGBA.Bounds(), blockRGBA, image.ZP, ghostMask, image.ZP, draw.Over)
+			ghostTex := peer.GetGLPeer().LoadTextureFromImage(ghostRGBA, rect)
+			l.ghostTextures[i] = &ghostTex
 		}
 	}
 }
@@ -202,6 +261,74 @@ func (l *LevelScene) initBackgroundImage() {
 // load textures for text labels
 func (l *LevelScene) initLabelSprites() {
 
+	switch l.snapshot.Mode {
+	case domain.SprintMode:
+		// race the clock to clear the lines
+		l.initTimerSprites(domain.BoardOffsetX + 20)
+		l.initLinesSprites()
+	case domain.UltraMode:
+		// score as much as possible before time runs out
+		l.initScoreSprites()
+		l.initTimerSprites(config.ScreenWidth - domain.UltraTimerOffsetX)
+	default:
+		l.initScoreSprites()
+		l.initLevelSprites()
+	}
+
+	// streaks
+	l.comboLabel = &simra.Sprite{}
+
+	l.comboLabel.W = float32(100)
+	l.comboLabel.H = float32(domain.BlockPixels)
+
+	// put bottom left screen, in line with the audio button
+	l.comboLabel.X = float32(domain.BoardOffsetX + 20)
+	l.comboLabel.Y = float32(domain.AudioButtonHeight)
+
+	simra.GetInstance().AddSprite("combo.png",
+		image.Rect(0, 0, 150, 40),
+		l.comboLabel)
+
+	l.comboDigits = l.initDigitSprites(l.comboLabel, domain.MaxStreakDigits)
+
+	l.backToBackLabel = &simra.Sprite{}
+
+	l.backToBackLabel.W = float32(100)
+	l.backToBackLabel.H = float32(domain.BlockPixels)
+
+	// put bottom middle screen
+	l.backToBackLabel.X = float32(config.ScreenWidth/2 + 20)
+	l.backToBackLabel.Y = float32(domain.AudioButtonHeight)
+
+	simra.GetInstance().AddSprite("b2b.png",
+		image.Rect(0, 0, 150, 40),
+		l.backToBackLabel)
+
+	l.backToBackDigits = l.initDigitSprites(l.backToBackLabel, domain.MaxStreakDigits)
+
+	// audio button
+
+	l.audioSprite = &simra.Sprite{}
+	l.audioSprite.W = float32(domain.AudioButtonWidth)
+	l.audioSprite.H = float32(domain.AudioButtonHeight)
+
+	// put bottom right screen
+	l.audioSprite.X = float32(config.ScreenWidth - domain.AudioButtonWidth)
+	l.audioSprite.Y = float32(domain.AudioButtonHeight)
+
+	simra.GetInstance().AddSprite("audio_on.png",
+		image.Rect(0, 0, domain.AudioButtonWidth, domain.AudioButtonHeight),
+		l.audioSprite)
+
+	// add listener
+	touchListener := &audioTouchListener{}
+	touchListener.parent = l
+	l.audioSprite.AddTouchListener(touchListener)
+}
+
+// initScoreSprites shows the score at the top left of the screen
+func (l *LevelScene) initScoreSprites() {
+
 	l.scoreLabel = &simra.Sprite{}
 
 	l.scoreLabel.W = float32(100)
@@ -215,28 +342,12 @@ func (l *LevelScene) initLabelSprites() {
 		image.Rect(0, 0, 150, 40),
 		l.scoreLabel)
 
-	lastDigitX := l.scoreLabel.X
-	lastDigitY := l.scoreLabel.Y
-
-	lastDigitX += float32(domain.BlockPixels)
 	// init score digits
-	l.scoreDigits = make([]*simra.Sprite, domain.MaxScoreDigits)
-	for i := 0; i < len(l.scoreDigits); i++ {
-		l.scoreDigits[i] = &simra.Sprite{}
-		l.scoreDigits[i].W = float32(domain.BlockPixels / 2)
-		l.scoreDigits[i].H = float32(domain.BlockPixels)
-
-		lastDigitX += float32(domain.BlockPixels / 2)
-		l.scoreDigits[i].X = lastDigitX
-		l.scoreDigits[i].Y = lastDigitY
-
-		simra.GetInstance().AddSprite("digits.png",
-			image.Rect(0, 0, domain.BlockPixels, domain.BlockPixels),
-			l.scoreDigits[i])
-		// replace texture straightaway
-		peer.GetSpriteContainer().ReplaceTexture(&l.scoreDigits[i].Sprite, *l.digitTextures[0])
+	l.scoreDigits = l.initDigitSprites(l.scoreLabel, domain.MaxScoreDigits)
+}
 
-	}
+// initLevelSprites shows the level at the top right of the screen
+func (l *LevelScene) initLevelSprites() {
 
 	l.levelLabel = &simra.Sprite{}
 
@@ -251,46 +362,110 @@ func (l *LevelScene) initLabelSprites() {
 		image.Rect(0, 0, 150, 40),
 		l.levelLabel)
 
-	lastDigitX = l.levelLabel.X
-	lastDigitY = l.levelLabel.Y
-
-	lastDigitX += float32(domain.BlockPixels)
 	// init level digits
-	l.levelDigits = make([]*simra.Sprite, domain.MaxLevelDigits)
-	for i := 0; i < len(l.levelDigits); i++ {
-		l.levelDigits[i] = &simra.Sprite{}
-		l.levelDigits[i].W = float32(domain.BlockPixels / 2)
-		l.levelDigits[i].H = float32(domain.BlockPixels)
+	l.levelDigits = l.initDigitSprites(l.levelLabel, domain.MaxLevelDigits)
+}
 
-		lastDigitX += float32(domain.BlockPixels / 2)
-		l.levelDigits[i].X = lastDigitX
-		l.levelDigits[i].Y = lastDigitY
-		simra.GetInstance().AddSprite("digits.png",
-			image.Rect(0, 0, domain.BlockPixels, domain.BlockPixels),
-			l.levelDigits[i])
-		// replace texture straightaway
-		peer.GetSpriteContainer().ReplaceTexture(&l.levelDigits[i].Sprite, *l.digitTextures[0])
+// initLinesSprites shows the lines left to clear at the top right of the screen
+func (l *LevelScene) initLinesSprites() {
+
+	l.linesLabel = &simra.Sprite{}
+
+	l.linesLabel.W = float32(100)
+	l.linesLabel.H = float32(domain.BlockPixels)
+
+	// put top right screen, leaving room for the extra digit
+	l.linesLabel.X = float32(config.ScreenWidth - 95 - domain.BlockPixels/2)
+	l.linesLabel.Y = float32(config.ScreenHeight - domain.BlockPixels/2 - 4)
 
+	simra.GetInstance().AddSprite("lines.png",
+		image.Rect(0, 0, 150, 40),
+		l.linesLabel)
+
+	l.linesDigits = l.initDigitSprites(l.linesLabel, domain.MaxLinesDigits)
+}
+
+// initTimerSprites shows minutes, seconds and hundredths
+// along the top of the screen following a time label at x
+func (l *LevelScene) initTimerSprites(x int) {
+
+	l.timeLabel = &simra.Sprite{}
+
+	l.timeLabel.W = float32(100)
+	l.timeLabel.H = float32(domain.BlockPixels)
+
+	l.timeLabel.X = float32(x)
+	l.timeLabel.Y = float32(config.ScreenHeight - domain.BlockPixels/2 - 4)
+
+	simra.GetInstance().AddSprite("time.png",
+		image.Rect(0, 0, 150, 40),
+		l.timeLabel)
+
+	lastDigitX := l.timeLabel.X + float32(domain.BlockPixels)
+	lastDigitY := l.timeLabel.Y
+
+	l.timerDigits = make([]*simra.Sprite, 0, 6)
+	l.timerSeparators = make([]*simra.Sprite, 0, 2)
+	for _, separator := range []string{"colon.png", "point.png", ""} {
+		for i := 0; i < 2; i++ {
+			digit := &simra.Sprite{}
+			digit.W = float32(domain.BlockPixels / 2)
+			digit.H = float32(domain.BlockPixels)
+
+			lastDigitX += float32(domain.BlockPixels / 2)
+			digit.X = lastDigitX
+			digit.Y = lastDigitY
+
+			simra.GetInstance().AddSprite("digits.png",
+				image.Rect(0, 0, domain.BlockPixels, domain.BlockPixels),
+				digit)
+			peer.GetSpriteContainer().ReplaceTexture(&digit.Sprite, *l.digitTextures[0])
+			l.timerDigits = append(l.timerDigits, digit)
+		}
+		if separator == "" {
+			continue
+		}
+
+		sprite := &simra.Sprite{}
+		sprite.W = float32(domain.TimerSeparatorWidth)
+		sprite.H = float32(domain.BlockPixels)
+
+		lastDigitX += float32(domain.TimerSeparatorWidth)
+		sprite.X = lastDigitX
+		sprite.Y = lastDigitY
+
+		simra.GetInstance().AddSprite(separator,
+			image.Rect(0, 0, 15, 40),
+			sprite)
+		l.timerSeparators = append(l.timerSeparators, sprite)
 	}
+}
 
-	// audio button
+// initDigitSprites adds a row of digits following a label
+func (l *LevelScene) initDigitSprites(label *simra.Sprite, count int) []*simra.Sprite {
 
-	l.audioSprite = &simra.Sprite{}
-	l.audioSprite.W = float32(domain.AudioButtonWidth)
-	l.audioSprite.H = float32(domain.AudioButtonHeight)
+	lastDigitX := label.X
+	lastDigitY := label.Y
 
-	// put bottom right screen
-	l.audioSprite.X = float32(config.ScreenWidth - domain.AudioButtonWidth)
-	l.audioSprite.Y = float32(domain.AudioButtonHeight)
+	lastDigitX += float32(domain.BlockPixels)
+	digits := make([]*simra.Sprite, count)
+	for i := 0; i < len(digits); i++ {
+		digits[i] = &simra.Sprite{}
+		digits[i].W = float32(domain.BlockPixels / 2)
+		digits[i].H = float32(domain.BlockPixels)
 
-	simra.GetInstance().AddSprite("audio_on.png",
-		image.Rect(0, 0, domain.AudioButtonWidth, domain.AudioButtonHeight),
-		l.audioSprite)
+		lastDigitX += float32(domain.BlockPixels / 2)
+		digits[i].X = lastDigitX
+		digits[i].Y = lastDigitY
 
-	// add listener
-	touchListener := &audioTouchListener{}
-	touchListener.parent = l
-	l.audioSprite.AddTouchListener(touchListener)
+		simra.GetInstance().AddSprite("digits.png",
+			image.Rect(0, 0, domain.BlockPixels, domain.BlockPixels),
+			digits[i])
+		// replace texture straightaway
+		peer.GetSpriteContainer().ReplaceTexture(&digits[i].Sprite, *l.digitTextures[0])
+
+	}
+	return digits
 }
 
 // displayGameOverSprite is only called at the end of a game
@@ -315,24 +490,64 @@ func (l *LevelScene) displayGameOverSprite() {
 
 }
 
-func (l *LevelScene) initDigitTextures() {
+// displayFinishedSprite is only called when a game reaches its goal
+func (l *LevelScene) displayFinishedSprite() {
+
+	if l.finishedLabel == nil {
+		l.finishedLabel = &simra.Sprite{}
+
+		// scale up the label to stand out like game over
+		l.finishedLabel.W = float32(216 * 3 / 2)
+		l.finishedLabel.H = float32(40 * 3 / 2)
+
+		// put in screen centre
+		centreX := config.ScreenWidth / 2
+		centreY := config.ScreenHeight / 2
+		l.finishedLabel.X = float32(centreX)
+		l.finishedLabel.Y = float32(centreY)
+
+		simra.GetInstance().AddSprite("finished.png",
+			image.Rect(0, 0, 216, 40),
+			l.finishedLabel)
+	}
+
+}
 
-	l.digitTextures = make(map[int]*sprite.SubTex, 10)
+// removeEndSprites removes the game over and finished labels
+func (l *LevelScene) removeEndSprites() {
+	if l.gameOverLabel != nil {
+		simra.GetInstance().RemoveSprite(l.gameOverLabel)
+		l.gameOverLabel = nil
+	}
+	if l.finishedLabel != nil {
+		simra.GetInstance().RemoveSprite(l.finishedLabel)
+		l.finishedLabel = nil
+	}
+}
 
+func (l *LevelScene) initDigitTextures() {
 	// digits image is a single image containing all the numbers
-	digitsImage, _, err := io.LoadImage("digits.png")
+	l.digitTextures = sliceTextures("digits.png", 10, domain.DigitsWidth, domain.DigitsHeight)
+}
+
+// sliceTextures slices an image of equal sized cells laid out
+// left to right into separate textures
+func sliceTextures(name string, count, width, height int) map[int]*sprite.SubTex {
+
+	textures := make(map[int]*sprite.SubTex, count)
+
+	sourceImage, _, err := io.LoadImage(name)
 	if err != nil {
 		panic(fmt.Sprintf("Error loading image: %s\n", err))
-	} else {
-		// slice image into separate textures
-		for i := 0; i < 10; i++ {
-			x := i * domain.DigitsWidth
-			y := 0
-			rect := image.Rect(x, y, x+domain.DigitsWidth, y+domain.DigitsHeight)
-			tex := peer.GetGLPeer().LoadTextureFromImage(digitsImage, rect)
-			l.digitTextures[i] = &tex
-		}
 	}
+	for i := 0; i < count; i++ {
+		x := i * width
+		y := 0
+		rect := image.Rect(x, y, x+width, y+height)
+		tex := peer.GetGLPeer().LoadTextureFromImage(sourceImage, rect)
+		textures[i] = &tex
+	}
+	return textures
 }
 
 // numberToDigits converts a number to an array of indexes for digit images
@@ -357,6 +572,10 @@ func numberToDigits(number int) []int {
 // scoreToDigits converts score to an array of digit image indexes
 func scoreToDigits(score int) []int {
 
+	// scores too big to show stay at the top score
+	if score > domain.MaxScore {
+		score = domain.MaxScore
+	}
 	numberDigits := numberToDigits(score)
 
 	if len(numberDigits) == domain.MaxScoreDigits {
@@ -386,6 +605,59 @@ func levelToDigits(level int) []int {
 	return numberDigits
 }
 
+// streakToDigits converts a combo or back to back streak to an array of digit image indexes
+func streakToDigits(streak int) []int {
+
+	numberDigits := numberToDigits(streak)
+
+	if len(numberDigits) >= domain.MaxStreakDigits {
+		// long streaks show the last digits
+		return numberDigits[len(numberDigits)-domain.MaxStreakDigits:]
+	}
+
+	// if not right length, zero pad result
+	diff := domain.MaxStreakDigits - len(numberDigits)
+	zeroDigits := make([]int, diff)
+	numberDigits = append(zeroDigits, numberDigits...)
+	return numberDigits
+}
+
+// linesToDigits converts the lines left in a sprint to an array of digit image indexes
+func linesToDigits(lines int) []int {
+
+	numberDigits := numberToDigits(lines)
+
+	if len(numberDigits) == domain.MaxLinesDigits {
+		return numberDigits
+	}
+
+	// if not right length, zero pad result
+	diff := domain.MaxLinesDigits - len(numberDigits)
+	zeroDigits := make([]int, diff)
+	numberDigits = append(zeroDigits, numberDigits...)
+	return numberDigits
+}
+
+// timeToDigits converts elapsed time to digit image indexes for
+// minutes, seconds and hundredths of a second
+func timeToDigits(elapsed time.Duration) []int {
+
+	hundredths := int(elapsed / (10 * time.Millisecond))
+	minutes := hundredths / 6000
+	if minutes > 99 {
+		// the timer stops at its limit
+		return []int{9, 9, 5, 9, 9, 9}
+	}
+	seconds := hundredths / 100 % 60
+	hundredths = hundredths % 100
+
+	return []int{
+		minutes / 10, minutes % 10,
+		seconds / 10, seconds % 10,
+		hundredths / 10, hundredths % 10,
+	}
+}
+
 func (l *LevelScene) redrawBackgroundImage() {
 
 	l.initBackgroundImage()
@@ -401,8 +673,7 @@ func (l *LevelScene) redrawBackgroundImage() {
 
 func (l *LevelScene) drawBlocks(sourceImage image.Image) image.Image {
 
-	gameBlocks := l.Game.GetBlocks()
-	blocks := *gameBlocks
+	blocks := l.snapshot.Board
 	boardWidth := len(blocks)
 
 	point := image.Point{X: 0, Y: 0}
@@ -420,10 +691,7 @@ func (l *LevelScene) drawBlocks(sourceImage image.Image) image.Image {
 				continue
 			}
 			blockImage := l.blockImages[block.Colour]
-			xCoord := (x * domain.BlockPixels) + domain.BoardOffsetX
-			yCoord := maxY - ((y + 2) * domain.BlockPixels) + domain.BoardOffsetY - domain.BlockPixels/2 - 4
-
-			rect := image.Rect(xCoord, yCoord, xCoord+domain.BlockPixels, yCoord+domain.BlockPixels)
+			rect := l.layout.imageRect(x, y, maxY)
 			if blockImage == nil {
 				continue
 			}
@@ -467,25 +735,46 @@ func DrawGrid(sourceImage image.Image, tileWidth int, tileHeight int) image.Imag
 
 func (l *LevelScene) initPlayerSprites() {
 
-	// playerBlocks & nextBlocks are moving sprites
-	game := l.Game
-	player := game.Player
-	playerBlocks := player.GetShapeBlocks()
-	nextBlocks := player.GetNextShapeBlocks()
+	// playerBlocks, nextBlocks & heldBlocks are moving sprites
+	snapshot := l.snapshot
+	playerBlocks := snapshot.Shape
+
+	// init ghost block sprites first so they are drawn underneath
+	l.ghostSprites = make([]*simra.Sprite, len(playerBlocks))
+	for i, _ := range playerBlocks {
+		ghostSprite := new(simra.Sprite)
+
+		ghostBlockX := playerBlocks[i].X + snapshot.GhostX
+		ghostBlockY := playerBlocks[i].Y + snapshot.GhostY
+		ghostSprite.W = float32(l.layout.blockPixels)
+		ghostSprite.H = float32(l.layout.blockPixels)
+
+		ghostSprite.X = l.layout.spriteX(ghostBlockX)
+		ghostSprite.Y = l.layout.spriteY(ghostBlockY)
+
+		blockImage := domain.SpriteNames[playerBlocks[i].Colour]
+		simra.GetInstance().AddSprite(blockImage,
+			image.Rect(0, 0, int(domain.BlockPixels), int(domain.BlockPixels)),
+			ghostSprite)
+		// replace with faded texture straightaway
+		peer.GetSpriteContainer().ReplaceTexture(&ghostSprite.Sprite, *l.ghostTextures[playerBlocks[i].Colour])
+
+		l.ghostSprites[i] = ghostSprite
+	}
 
 	// init current block sprites
 	l.playerSprites = make([]*simra.Sprite, len(playerBlocks))
 	for i, _ := range playerBlocks {
 		playerSprite := new(simra.Sprite)
 
-		playerBlockX := playerBlocks[i].X + player.X
-		playerBlockY := playerBlocks[i].Y + player.Y
-		playerSprite.W = float32(domain.BlockPixels)
-		playerSprite.H = float32(domain.BlockPixels)
+		playerBlockX := playerBlocks[i].X + snapshot.X
+		playerBlockY := playerBlocks[i].Y + snapshot.Y
+		playerSprite.W = float32(l.layout.blockPixels)
+		playerSprite.H = float32(l.layout.blockPixels)
 
 		// put center of screen
-		playerSprite.X = float32(domain.BlockPixels*playerBlockX + domain.BlockPixels/2 + domain.BoardOffsetX)
-		playerSprite.Y = float32(domain.BlockPixels*playerBlockY + domain.BlockPixels/2 + domain.BoardOffsetY)
+		playerSprite.X = l.layout.spriteX(playerBlockX)
+		playerSprite.Y = l.layout.spriteY(playerBlockY)
 
 		// lookup blockImage for sprite colour
 		blockImage := domain.SpriteNames[playerBlocks[i].Colour]
@@ -498,29 +787,84 @@ func (l *LevelScene) initPlayerSprites() {
 
 	centreX := config.ScreenWidth / 2
 
-	// init next block sprites
-	l.nextBlockSprites = make([]*simra.Sprite, len(nextBlocks))
-	for i, _ := range nextBlocks {
-		nextSprite := new(simra.Sprite)
+	// next queue to the right of centre, held shape to the left
+	l.nextBlockSprites = nil
+	for i, blocks := range snapshot.Queue {
+		pixels, offsetX, offsetY := queuePreview(l.layout, len(snapshot.Board), i)
+		l.nextBlockSprites = append(l.nextBlockSprites, initPreviewSprites(blocks, pixels, offsetX, offsetY)...)
+	}
+	l.heldBlockSprites = initPreviewSprites(snapshot.HeldShape, domain.NextBlockPixels,
+		centreX-domain.HoldOffsetX, config.ScreenHeight-domain.NextOffsetY)
+
+}
+
+// queuePreview returns the block size and offset of a shape in the next queue
+// for a board of the given width including its walls.
+// The front shape sits at the top right of centre, the rest run down
+// the right of the screen over the board's right wall, each no bigger than the shape before
+// and shrunk further when a wide board leaves less room beside its playfield
+func queuePreview(layout boardLayout, width, index int) (pixels, offsetX, offsetY int) {
+	if index == 0 {
+		return domain.NextBlockPixels, config.ScreenWidth/2 + domain.NextOffsetX, config.ScreenHeight - domain.NextOffsetY
+	}
+
+	// shapes are up to four blocks wide and two high with a block between them,
+	// sprites are positioned by their centres
+	wallX := layout.offsetX + (width-1)*layout.blockPixels
+	room := config.ScreenWidth - domain.QueueOffsetX - wallX
+	top := config.ScreenHeight - 2*domain.NextOffsetY
+	for i := 1; i < index; i++ {
+		top -= 3 * queuePixels(i, room)
+	}
+	pixels = queuePixels(index, room)
+	offsetX = config.ScreenWidth - domain.QueueOffsetX - 4*pixels + pixels/2
+	offsetY = top - 3*pixels/2
+	return pixels, offsetX, offsetY
+}
+
+// queuePixels returns the block size of a shape further down the next queue,
+// small enough for a shape four blocks wide to fit in the room beside the board
+func queuePixels(index, room int) int {
+	pixels := domain.NextBlockPixels - (index+1)*domain.QueueShrinkPixels
+	if room/4 < pixels {
+		return room / 4
+	}
+	return pixels
+}
+
+// initPreviewSprites creates small sprites to preview a shape, with blocks of the given size
+func initPreviewSprites(blocks []domain.Block, pixels, offsetX, offsetY int) []*simra.Sprite {
 
-		nextSprite.W = float32(domain.NextBlockPixels)
-		nextSprite.H = float32(domain.NextBlockPixels)
+	sprites := make([]*simra.Sprite, len(blocks))
+	for i, _ := range blocks {
+		previewSprite := new(simra.Sprite)
 
-		nextSprite.X = float32(domain.NextBlockPixels*nextBlocks[i].X + centreX)
-		nextSprite.Y = float32(domain.NextBlockPixels*nextBlocks[i].Y + config.ScreenHeight - domain.NextOffsetY)
+		previewSprite.W = float32(pixels)
+		previewSprite.H = float32(pixels)
+
+		previewSprite.X = float32(pixels*blocks[i].X + offsetX)
+		previewSprite.Y = float32(pixels*blocks[i].Y + offsetY)
 
 		// lookup blockImage for sprite colour
-		nextImage := domain.SpriteNames[nextBlocks[i].Colour]
-		simra.GetInstance().AddSprite(nextImage,
+		previewImage := domain.SpriteNames[blocks[i].Colour]
+		simra.GetInstance().AddSprite(previewImage,
 			image.Rect(0, 0, int(domain.BlockPixels), int(domain.BlockPixels)),
-			nextSprite)
+			previewSprite)
 
-		l.nextBlockSprites[i] = nextSprite
+		sprites[i] = previewSprite
 	}
 
+	return sprites
 }
 
 func (l *LevelScene) removePlayerSprites() {
+	for i, _ := range l.ghostSprites {
+		if l.ghostSprites[i] == nil {
+			continue
+		}
+		simra.GetInstance().RemoveSprite(l.ghostSprites[i])
+	}
+	l.ghostSprites = nil
 	for i, _ := range l.playerSprites {
 		if l.playerSprites[i] == nil {
 			continue
@@ -534,49 +878,105 @@ func (l *LevelScene) removePlayerSprites() {
 		}
 		simra.GetInstance().RemoveSprite(l.nextBlockSprites[i])
 	}
-	l.playerSprites = nil
+	l.nextBlockSprites = nil
+	for i, _ := range l.heldBlockSprites {
+		if l.heldBlockSprites[i] == nil {
+			continue
+		}
+		simra.GetInstance().RemoveSprite(l.heldBlockSprites[i])
+	}
+	l.heldBlockSprites = nil
 }
 
 func (l *LevelScene) updateLabelSprites() {
-	game := l.Game
+	snapshot := l.snapshot
 
 	// convert score
-	scoreDigits := scoreToDigits(game.Player.Score)
+	scoreDigits := scoreToDigits(snapshot.Score)
 
 	for i, value := range scoreDigits {
-		if l.scoreDigits[i] == nil {
+		if i >= len(l.scoreDigits) || l.scoreDigits[i] == nil {
 			continue
 		}
 		peer.GetSpriteContainer().ReplaceTexture(&l.scoreDigits[i].Sprite, *l.digitTextures[value])
 	}
 
 	// convert level
-	levelDigits := levelToDigits(game.Player.Level)
+	levelDigits := levelToDigits(snapshot.Level)
 
 	for i, value := range levelDigits {
-		if l.levelDigits[i] == nil {
+		if i >= len(l.levelDigits) || l.levelDigits[i] == nil {
 			continue
 		}
 		peer.GetSpriteContainer().ReplaceTexture(&l.levelDigits[i].Sprite, *l.digitTextures[value])
 	}
 
+	// convert time, counting down when the game has a time limit
+	timer := snapshot.Elapsed
+	if snapshot.TimeLimit > 0 {
+		timer = snapshot.TimeLimit - snapshot.Elapsed
+		if timer < 0 {
+			timer = 0
+		}
+	}
+	timerDigits := timeToDigits(timer)
+
+	for i, value := range timerDigits {
+		if i >= len(l.timerDigits) || l.timerDigits[i] == nil {
+			continue
+		}
+		peer.GetSpriteContainer().ReplaceTexture(&l.timerDigits[i].Sprite, *l.digitTextures[value])
+	}
+
+	// convert lines left to clear
+	remaining := snapshot.LineTarget - snapshot.TotalRows
+	if remaining < 0 {
+		remaining = 0
+	}
+	linesDigits := linesToDigits(remaining)
+
+	for i, value := range linesDigits {
+		if i >= len(l.linesDigits) || l.linesDigits[i] == nil {
+			continue
+		}
+		peer.GetSpriteContainer().ReplaceTexture(&l.linesDigits[i].Sprite, *l.digitTextures[value])
+	}
+
+	// convert streaks
+	comboDigits := streakToDigits(snapshot.Combo)
+
+	for i, value := range comboDigits {
+		if l.comboDigits[i] == nil {
+			continue
+		}
+		peer.GetSpriteContainer().ReplaceTexture(&l.comboDigits[i].Sprite, *l.digitTextures[value])
+	}
+
+	backToBackDigits := streakToDigits(snapshot.BackToBack)
+
+	for i, value := range backToBackDigits {
+		if l.backToBackDigits[i] == nil {
+			continue
+		}
+		peer.GetSpriteContainer().ReplaceTexture(&l.backToBackDigits[i].Sprite, *l.digitTextures[value])
+	}
+
 	// update audio sprite (Based on audio state)
 	if l.audioSprite != nil {
-		peer.GetSpriteContainer().ReplaceTexture(&l.audioSprite.Sprite, *l.audioTextures[game.IsAudioPlaying()])
+		peer.GetSpriteContainer().ReplaceTexture(&l.audioSprite.Sprite, *l.audioTextures[snapshot.AudioPlaying])
 	}
 
 }
 
 func (l *LevelScene) updatePlayerSprites() {
-	game := l.Game
+	snapshot := l.snapshot
 
 	// init Player sprites if they do not exist
 	if l.playerSprites == nil {
 		l.initPlayerSprites()
 	}
 
-	player := game.Player
-	playerBlocks := player.GetShapeBlocks()
+	playerBlocks := snapshot.Shape
 
 	for i, _ := range playerBlocks {
 		playerSprite := l.playerSprites[i]
@@ -585,15 +985,28 @@ func (l *LevelScene) updatePlayerSprites() {
 			continue
 		}
 
-		playerBlockX := playerBlocks[i].X + player.X
-		playerBlockY := playerBlocks[i].Y + player.Y
-		playerSprite.W = float32(domain.BlockPixels)
-		playerSprite.H = float32(domain.BlockPixels)
+		playerBlockX := playerBlocks[i].X + snapshot.X
+		playerBlockY := playerBlocks[i].Y + snapshot.Y
+		playerSprite.W = float32(l.layout.blockPixels)
+		playerSprite.H = float32(l.layout.blockPixels)
 
 		// put center of screen
-		playerSprite.X = float32(domain.BlockPixels*playerBlockX + domain.BlockPixels/2 + domain.BoardOffsetX)
-		playerSprite.Y = float32(domain.BlockPixels*playerBlockY + domain.BlockPixels/2 + domain.BoardOffsetY)
+		playerSprite.X = l.layout.spriteX(playerBlockX)
+		playerSprite.Y = l.layout.spriteY(playerBlockY)
+
+	}
+
+	for i, _ := range playerBlocks {
+		if i >= len(l.ghostSprites) || l.ghostSprites[i] == nil {
+			continue
+		}
+		ghostSprite := l.ghostSprites[i]
+
+		ghostBlockX := playerBlocks[i].X + snapshot.GhostX
+		ghostBlockY := playerBlocks[i].Y + snapshot.GhostY
 
+		ghostSprite.X = l.layout.spriteX(ghostBlockX)
+		ghostSprite.Y = l.layout.spriteY(ghostBlockY)
 	}
 
 }
@@ -605,20 +1018,40 @@ type touchListener struct {
 	touchBeginX, touchBeginY     float32
 	touchCurrentX, touchCurrentY float32
 	touchEndX, touchEndY         float32
+	touchStartX, touchStartY     float32
 	touchStart                   time.Time
+	moved                        bool
+	fingers, maxFingers          int
 }
 
 func (t *touchListener) OnTouchBegin(x, y float32) {
+	// count fingers for multi finger taps
+	t.fingers++
+	if t.fingers > t.maxFingers {
+		t.maxFingers = t.fingers
+	}
+	if t.fingers > 1 {
+		return
+	}
+
 	t.touchBeginX = x
 	t.touchBeginY = y
 	t.touchCurrentX = x
 	t.touchCurrentY = y
 	t.touching = true
+	t.touchStartX = x
+	t.touchStartY = y
 	t.touchStart = time.Now()
+	t.moved = false
 }
 
 func (t *touchListener) OnTouchMove(x, y float32) {
 
+	if t.maxFingers > 1 {
+		// multi finger taps don't move the shape
+		return
+	}
+
 	if !t.touching {
 		// update values
 		t.touchBeginX = x
@@ -635,13 +1068,14 @@ func (t *touchListener) OnTouchMove(x, y float32) {
 	yMovement := t.touchBeginY - t.touchCurrentY
 
 	// check if touch is near edge of block
-	moveTolerance := float32(domain.BlockPixels) - 5 // allow for some lag when moving blocks quickly
+	moveTolerance := float32(t.parent.layout.blockPixels) - 5 // allow for some lag when moving blocks quickly
 
 	if xMovement >= moveTolerance {
 		t.parent.Game.MoveLeft()
 		// reset begin values
 		t.touchBeginX = x
 		t.touchBeginY = y
+		t.moved = true
 		return
 	}
 
@@ -650,31 +1084,106 @@ func (t *touchListener) OnTouchMove(x, y float32) {
 		// reset begin values
 		t.touchBeginX = x
 		t.touchBeginY = y
+		t.moved = true
 		return
 	}
 
 	if yMovement >= moveTolerance {
+		if time.Now().Sub(t.touchStart) < domain.FlickDuration*time.Millisecond {
+			// wait and see if this is a flick, which hard drops when the touch ends
+			return
+		}
 		t.parent.Game.MoveDown()
+		// keep dropping quickly while touch is held
+		t.parent.Game.SetSoftDrop(true)
 		// reset begin values
 		t.touchBeginX = x
 		t.touchBeginY = y
+		t.moved = true
+		return
+	}
+
+	if yMovement <= -moveTolerance {
+		// swipe up to hold shape
+		t.parent.Game.Hold()
+		// reset begin values
+		t.touchBeginX = x
+		t.touchBeginY = y
+		t.moved = true
 		return
 	}
 
 }
 
 func (t *touchListener) OnTouchEnd(x, y float32) {
-	if t.parent.Game.GetState() == domain.GameOver {
-		t.parent.Game.StartMenu()
+	if state := t.parent.Game.GetState(); state == domain.GameOver || state == domain.Finished {
+		if t.parent.Game.ResultRank() > 0 {
+			// ask for initials to go with the new high score
+			simra.GetInstance().SetScene(&HighScoreScene{Game: t.parent.Game, Leaderboard: t.parent.Game.Result().Key(), Entry: true})
+		} else {
+			t.parent.Game.StartMenu()
+		}
 	}
 
+	// wait for the last finger to lift
+	t.fingers--
+	if t.fingers > 0 {
+		return
+	}
+	t.fingers = 0
+	fingers := t.maxFingers
+	t.maxFingers = 0
+
 	t.touching = false
 	t.touchEndX = x
 	t.touchEndY = y
 	duration := time.Now().Sub(t.touchStart)
+	t.parent.Game.SetSoftDrop(false)
+
+	// a fast flick downwards drops the shape straight to the bottom
+	flickDistance := t.touchStartY - y
+	if flickDistance >= domain.FlickPixels && duration < domain.FlickDuration*time.Millisecond {
+		t.parent.Game.HardDrop()
+		return
+	}
+
+	// holding still ends games that can't be lost
+	if !t.moved && duration >= domain.QuitDuration*time.Millisecond && t.parent.snapshot.Mode == domain.ZenMode {
+		t.parent.Game.EndGame()
+		return
+	}
 
-	if duration.Nanoseconds() < 500000000 {
-		t.parent.Game.Rotate()
+	// quick taps rotate, swipes have already moved the shape
+	if duration.Nanoseconds() < 500000000 && !t.moved {
+		switch fingers {
+		case 1:
+			t.parent.Game.Rotate()
+		case 2:
+			t.parent.Game.RotateCCW()
+		default:
+			t.parent.Game.Rotate180()
+		}
+	}
+}
+
+// keyBindings maps desktop keys to game commands
+var keyBindings = map[key.Code]domain.Command{
+	key.CodeLeftArrow:  domain.MoveLeftCommand,
+	key.CodeRightArrow: domain.MoveRightCommand,
+	key.CodeDownArrow:  domain.MoveDownCommand,
+	key.CodeUpArrow:    domain.RotateCommand,
+	key.CodeX:          domain.RotateCommand,
+	key.CodeZ:          domain.RotateCCWCommand,
+	key.CodeA:          domain.Rotate180Command,
+	key.CodeSpacebar:   domain.HardDropCommand,
+	key.CodeC:          domain.HoldCommand,
+	key.CodeEscape:     domain.EndGameCommand,
+}
+
+// OnKeyDown carries out the command bound to a key pressed on desktop
+func (l *LevelScene) OnKeyDown(code key.Code) {
+	if command, ok := keyBindings[code]; ok {
+		l.Game.Execute(command)
 	}
 }
 
@@ -702,19 +1211,39 @@ func (l *LevelScene) Drive() {
 		return
 	}
 
-	if l.Game.IsBoardDirty() {
+	l.draw()
+
+	if l.snapshot.State == domain.Menu {
+		simra.GetInstance().SetScene(&TitleScene{Game: l.Game})
+	}
+}
+
+// draw takes a snapshot of the game and updates the sprites to match
+func (l *LevelScene) draw() {
+
+	// clean board before taking the snapshot so changes made
+	// while drawing are picked up next frame
+	dirty := l.Game.IsBoardDirty()
+	if dirty {
+		l.Game.CleanBoard()
+	}
+	l.snapshot = l.Game.Snapshot()
+
+	if dirty {
 		// redraw board
 		l.removePlayerSprites()
 		l.redrawBackgroundImage()
-		l.Game.CleanBoard()
 	}
 	l.updateLabelSprites()
 	l.updatePlayerSprites()
 
-	if l.Game.GetState() == domain.GameOver {
+	switch l.snapshot.State {
+	case domain.GameOver:
 		l.displayGameOverSprite()
-	}
-	if l.Game.GetState() == domain.Menu {
-		simra.GetInstance().SetScene(&TitleScene{Game: l.Game})
+	case domain.Finished:
+		l.displayFinishedSprite()
+	case domain.Playing:
+		// replays can seek back to before the game ended
+		l.removeEndSprites()
 	}
 }
@@ -3,8 +3,11 @@ package scene
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/telecoda/go-teletris/domain"
+	"github.com/telecoda/go-teletris/scene/config"
+	"golang.org/x/mobile/event/key"
 )
 
 func TestNumberToDigits(t *testing.T) {
@@ -23,3 +26,177 @@ func TestNumberToDigits(t *testing.T) {
 		t.Errorf("ScoreDigits incorrect Expected: %d got: %d", expected, digits)
 	}
 }
+
+func TestScoreToDigits(t *testing.T) {
+
+	if digits := scoreToDigits(4567); !reflect.DeepEqual(digits, []int{0, 0, 4, 5, 6, 7}) {
+		t.Errorf("ScoreDigits incorrect Expected: %d got: %d", []int{0, 0, 4, 5, 6, 7}, digits)
+	}
+
+	// scores too big for the digits stay at the top score
+	expected := []int{9, 9, 9, 9, 9, 9}
+	if digits := scoreToDigits(1234567); !reflect.DeepEqual(digits, expected) {
+		t.Errorf("ScoreDigits incorrect Expected: %d got: %d", expected, digits)
+	}
+}
+
+func TestStreakToDigits(t *testing.T) {
+
+	tests := []struct {
+		streak   int
+		expected []int
+	}{
+		{streak: 0, expected: []int{0, 0}},
+		{streak: 7, expected: []int{0, 7}},
+		{streak: 42, expected: []int{4, 2}},
+		{streak: 123, expected: []int{2, 3}},
+	}
+
+	for _, test := range tests {
+		digits := streakToDigits(test.streak)
+		if !reflect.DeepEqual(digits, test.expected) {
+			t.Errorf("StreakDigits incorrect Expected: %d got: %d", test.expected, digits)
+		}
+	}
+}
+
+func TestBoardLayout(t *testing.T) {
+
+	// the standard board is drawn where it always has been
+	standard := newBoardLayout(domain.StandardBoard)
+	expected := boardLayout{blockPixels: domain.BlockPixels, offsetX: domain.BoardOffsetX, offsetY: domain.BoardOffsetY}
+	if standard != expected {
+		t.Errorf("Standard layout incorrect Expected: %+v got: %+v", expected, standard)
+	}
+
+	for _, size := range []domain.BoardSize{domain.NarrowBoard, domain.WideBoard, domain.TallBoard} {
+		layout := newBoardLayout(size)
+		right := layout.offsetX + size.Width*layout.blockPixels
+		top := layout.offsetY + size.Height*layout.blockPixels
+		if layout.offsetX < 0 || right > config.ScreenWidth {
+			t.Errorf("Board %v does not fit across the screen: %d to %d", size, layout.offsetX, right)
+		}
+		if top > domain.BoardOffsetY+domain.StandardBoard.Height*domain.BlockPixels {
+			t.Errorf("Board %v overlaps the info panel: %d", size, top)
+		}
+	}
+}
+
+// headlessGame returns a marathon in play without audio or a real time driver,
+// the same as a replay plays back
+func headlessGame(seed int64) *domain.Game {
+	options := domain.ModeOptions(domain.MarathonMode)
+	return domain.Replay{
+		Version:    domain.ReplayVersion,
+		Seed:       seed,
+		Mode:       options.Mode,
+		Randomizer: options.Randomizer,
+		BoardSize:  options.BoardSize,
+		LockDelay:  options.LockDelay,
+		LockResets: options.LockResets,
+		Previews:   options.Previews,
+		Gravity:    options.Gravity,
+	}.NewGame()
+}
+
+func TestFlickLocksOneShape(t *testing.T) {
+
+	game := headlessGame(7)
+
+	// let the shape fall until it rests on the floor
+	for snapshot := game.Snapshot(); snapshot.Y != snapshot.GhostY; snapshot = game.Snapshot() {
+		game.Step()
+	}
+
+	locks := 0
+	game.Subscribe(domain.EventListenerFunc(func(event domain.Event) {
+		if event.Type == domain.BlockDownEvent {
+			locks++
+		}
+	}))
+
+	level := &LevelScene{Game: game, layout: newBoardLayout(game.BoardSize()), snapshot: game.Snapshot()}
+	listener := &touchListener{parent: level}
+	x, y := float32(config.ScreenWidth/2), float32(config.ScreenHeight/2)
+	listener.OnTouchBegin(x, y)
+	for i := 1; i <= 5; i++ {
+		listener.OnTouchMove(x, y-float32(i*domain.BlockPixels))
+	}
+	listener.OnTouchEnd(x, y-float32(5*domain.BlockPixels))
+
+	if locks != 1 {
+		t.Errorf("Expected a flick to lock one shape received: %d", locks)
+	}
+}
+
+func TestKeysReachTheGame(t *testing.T) {
+
+	game := headlessGame(7)
+	level := &LevelScene{Game: game}
+	listenForKeys(level)
+	OnKeyDown(key.CodeZ)
+	OnKeyDown(key.CodeA)
+	OnKeyDown(key.CodeQ)
+	stopListeningForKeys(level)
+	OnKeyDown(key.CodeX)
+
+	expected := []domain.Command{domain.RotateCCWCommand, domain.Rotate180Command}
+	inputs := game.Replay().Inputs
+	if len(inputs) != len(expected) {
+		t.Fatalf("Expected commands: %v received: %+v", expected, inputs)
+	}
+	for i, input := range inputs {
+		if input.Command != expected[i] {
+			t.Errorf("Expected command: %d received: %d", expected[i], input.Command)
+		}
+	}
+}
+
+func TestTimeToDigits(t *testing.T) {
+
+	elapsed := 12*time.Minute + 34*time.Second + 567*time.Millisecond
+	expected := []int{1, 2, 3, 4, 5, 6}
+
+	digits := timeToDigits(elapsed)
+	if !reflect.DeepEqual(digits, expected) {
+		t.Errorf("TimeDigits incorrect Expected: %d got: %d", expected, digits)
+	}
+
+	digits = timeToDigits(100 * time.Minute)
+	expected = []int{9, 9, 5, 9, 9, 9}
+	if !reflect.DeepEqual(digits, expected) {
+		t.Errorf("TimeDigits incorrect Expected: %d got: %d", expected, digits)
+	}
+}
+
+func TestQueuePreview(t *testing.T) {
+
+	for _, size := range []domain.BoardSize{domain.StandardBoard, domain.NarrowBoard, domain.WideBoard, domain.TallBoard} {
+		layout := newBoardLayout(size)
+		pixels, offsetX, offsetY := queuePreview(layout, size.Width, 0)
+		if pixels != domain.NextBlockPixels || offsetX != config.ScreenWidth/2+domain.NextOffsetX ||
+			offsetY != config.ScreenHeight-domain.NextOffsetY {
+			t.Errorf("Expected front of queue at the top received: %d pixels at %d,%d", pixels, offsetX, offsetY)
+		}
+
+		// the rest run down the right of the screen clear of the board's playfield
+		playfieldRight := layout.offsetX + (size.Width-1)*layout.blockPixels
+		lastPixels, lastBottom := pixels, config.ScreenHeight-domain.BoardOffsetY
+		for i := 1; i < domain.MaxPreviews; i++ {
+			pixels, offsetX, offsetY := queuePreview(layout, size.Width, i)
+			left, right := offsetX-pixels/2, offsetX+4*pixels-pixels/2
+			top, bottom := offsetY+2*pixels-pixels/2, offsetY-pixels/2
+			if pixels > lastPixels || pixels <= 0 {
+				t.Errorf("Expected board %v shape %d no bigger than %d pixels received: %d", size, i, lastPixels, pixels)
+			}
+			if left < playfieldRight || right > config.ScreenWidth {
+				t.Errorf("Expected board %v shape %d right of the playfield from %d received: %d to %d",
+					size, i, playfieldRight, left, right)
+			}
+			if top > lastBottom {
+				t.Errorf("Expected board %v shape %d below %d received top: %d", size, i, lastBottom, top)
+			}
+			lastPixels, lastBottom = pixels, bottom
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package scene
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/telecoda/go-teletris/domain"
+	"github.com/telecoda/go-teletris/scene/config"
+	"github.com/telecoda/gomo-simra/simra"
+)
+
+// modeButton is a game mode that can be picked from the menu
+type modeButton struct {
+	mode      domain.GameMode
+	timeLimit time.Duration // 0 keeps the time limit of the mode
+	image     string
+	width     int
+}
+
+var modeButtons = []modeButton{
+	{mode: domain.MarathonMode, image: "marathon.png", width: 222},
+	{mode: domain.SprintMode, image: "sprint.png", width: 162},
+	{mode: domain.UltraMode, timeLimit: domain.UltraDuration, image: "ultra.png", width: 243},
+	{mode: domain.UltraMode, timeLimit: domain.ShortUltraDuration, image: "ultra_short.png", width: 243},
+	{mode: domain.ZenMode, image: "zen.png", width: 87},
+}
+
+// options returns the options of the game the button starts
+func (b modeButton) options() domain.GameOptions {
+	options := domain.ModeOptions(b.mode)
+	if b.timeLimit > 0 {
+		options.TimeLimit = b.timeLimit
+	}
+	return options
+}
+
+// ModeScene lets the player pick a game mode before the intro
+type ModeScene struct {
+	sync.Mutex
+	Game         *domain.Game
+	background   *simra.Sprite
+	modeSprites  []*simra.Sprite
+	scoresSprite *simra.Sprite
+	replaySprite *simra.Sprite
+}
+
+// Initialize initializes ModeScene
+func (m *ModeScene) Initialize() {
+	simra.GetInstance().SetDesiredScreenSize(config.ScreenWidth, config.ScreenHeight)
+
+	// initialize sprites
+	m.initialize()
+}
+
+func (m *ModeScene) initialize() {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	m.initBackground()
+	m.initModeSprites()
+	m.initScoresSprite()
+	m.initReplaySprite()
+}
+
+func (m *ModeScene) Destroy() {
+	go m.destroy()
+}
+
+func (m *ModeScene) destroy() {
+
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	m.background = nil
+	for n, _ := range m.modeSprites {
+		m.modeSprites[n].RemoveAllTouchListener()
+		m.modeSprites[n] = nil
+	}
+	if m.scoresSprite != nil {
+		m.scoresSprite.RemoveAllTouchListener()
+		m.scoresSprite = nil
+	}
+	if m.replaySprite != nil {
+		m.replaySprite.RemoveAllTouchListener()
+		m.replaySprite = nil
+	}
+	runtime.GC()
+}
+
+func (m *ModeScene) initBackground() {
+	// add background sprite
+	m.background = &simra.Sprite{}
+	m.background.W = float32(config.ScreenWidth)
+	m.background.H = float32(config.ScreenHeight)
+
+	// put center of screen
+	m.background.X = config.ScreenWidth / 2
+	m.background.Y = config.ScreenHeight / 2
+
+	simra.GetInstance().AddSprite("background.png",
+		image.Rect(0, 0, int(m.background.W), int(m.background.H)),
+		m.background)
+}
+
+func (m *ModeScene) initModeSprites() {
+
+	m.modeSprites = make([]*simra.Sprite, len(modeButtons))
+
+	// stack buttons down the middle of the screen
+	topY := config.ScreenHeight/2 + (len(modeButtons)-1)*domain.MenuButtonSpacing/2
+	for n, button := range modeButtons {
+		m.modeSprites[n] = &simra.Sprite{}
+		m.modeSprites[n].W = float32(button.width)
+		m.modeSprites[n].H = float32(domain.MenuButtonHeight)
+
+		m.modeSprites[n].X = config.ScreenWidth / 2
+		m.modeSprites[n].Y = float32(topY - n*domain.MenuButtonSpacing)
+
+		simra.GetInstance().AddSprite(button.image,
+			image.Rect(0, 0, button.width, domain.MenuButtonHeight),
+			m.modeSprites[n])
+
+		m.modeSprites[n].AddTouchListener(&modeTouchListener{parent: m, button: button})
+	}
+}
+
+// initScoresSprite adds a button below the modes to show the high scores
+func (m *ModeScene) initScoresSprite() {
+
+	topY := config.ScreenHeight/2 + (len(modeButtons)-1)*domain.MenuButtonSpacing/2
+
+	m.scoresSprite = &simra.Sprite{}
+	m.scoresSprite.W = float32(168)
+	m.scoresSprite.H = float32(domain.MenuButtonHeight)
+
+	m.scoresSprite.X = config.ScreenWidth / 2
+	m.scoresSprite.Y = float32(topY - len(modeButtons)*domain.MenuButtonSpacing)
+
+	simra.GetInstance().AddSprite("scores.png",
+		image.Rect(0, 0, 168, domain.MenuButtonHeight),
+		m.scoresSprite)
+
+	m.scoresSprite.AddTouchListener(&scoresTouchListener{parent: m})
+}
+
+// initReplaySprite adds a button below the high scores to watch the last game again,
+// it is only shown once a game has been recorded
+func (m *ModeScene) initReplaySprite() {
+
+	replay, err := domain.ReadReplay(m.Game.ReplayPath())
+	if err != nil {
+		return
+	}
+
+	topY := config.ScreenHeight/2 + (len(modeButtons)-1)*domain.MenuButtonSpacing/2
+
+	m.replaySprite = &simra.Sprite{}
+	m.replaySprite.W = float32(165)
+	m.replaySprite.H = float32(domain.MenuButtonHeight)
+
+	m.replaySprite.X = config.ScreenWidth / 2
+	m.replaySprite.Y = float32(topY - (len(modeButtons)+1)*domain.MenuButtonSpacing)
+
+	simra.GetInstance().AddSprite("replay.png",
+		image.Rect(0, 0, 165, domain.MenuButtonHeight),
+		m.replaySprite)
+
+	m.replaySprite.AddTouchListener(&replayButtonTouchListener{parent: m, replay: replay})
+}
+
+func (m *ModeScene) Drive() {
+}
+
+// modeTouchListener starts the intro for the mode that was tapped
+type modeTouchListener struct {
+	parent *ModeScene
+	button modeButton
+}
+
+func (t *modeTouchListener) OnTouchBegin(x, y float32) {
+}
+
+func (t *modeTouchListener) OnTouchMove(x, y float32) {
+}
+
+func (t *modeTouchListener) OnTouchEnd(x, y float32) {
+	t.parent.Game.SetMode(t.button.mode)
+	if t.button.timeLimit > 0 {
+		t.parent.Game.SetTimeLimit(t.button.timeLimit)
+	}
+	// scene end. go to next scene
+	simra.GetInstance().SetScene(&IntroScene{Game: t.parent.Game})
+}
+
+// scoresTouchListener shows the high scores
+type scoresTouchListener struct {
+	parent *ModeScene
+}
+
+func (t *scoresTouchListener) OnTouchBegin(x, y float32) {
+}
+
+func (t *scoresTouchListener) OnTouchMove(x, y float32) {
+}
+
+func (t *scoresTouchListener) OnTouchEnd(x, y float32) {
+	// start with the last game played, unranked games fall back to the first table
+	key := stepLeaderboard(t.parent.Game.LeaderboardKey(), 0)
+	simra.GetInstance().SetScene(&HighScoreScene{Game: t.parent.Game, Leaderboard: key})
+}
+
+// replayButtonTouchListener plays back the last game
+type replayButtonTouchListener struct {
+	parent *ModeScene
+	replay domain.Replay
+}
+
+func (t *replayButtonTouchListener) OnTouchBegin(x, y float32) {
+}
+
+func (t *replayButtonTouchListener) OnTouchMove(x, y float32) {
+}
+
+func (t *replayButtonTouchListener) OnTouchEnd(x, y float32) {
+	simra.GetInstance().SetScene(&ReplayScene{Game: t.parent.Game, Replay: t.replay})
+}
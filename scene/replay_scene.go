@@ -0,0 +1,237 @@
+package scene
+
+import (
+	"image"
+	"time"
+
+	"github.com/telecoda/go-teletris/domain"
+	"github.com/telecoda/go-teletris/scene/config"
+	"github.com/telecoda/gomo-simra/simra"
+	"github.com/telecoda/gomo-simra/simra/peer"
+	"golang.org/x/mobile/event/key"
+)
+
+// replaySpeeds are the speeds playback steps through
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8}
+
+// ReplayScene plays back a recorded game, drawing it exactly as LevelScene does.
+// Tap to pause, swipe left or right to seek and up or down to change speed
+type ReplayScene struct {
+	Game   *domain.Game
+	Replay domain.Replay
+
+	player      *domain.ReplayPlayer
+	level       *LevelScene
+	speedDigits []*simra.Sprite
+	speedPoint  *simra.Sprite
+	lastDrive   time.Time
+	frame       int
+}
+
+// Initialize initializes ReplayScene
+func (r *ReplayScene) Initialize() {
+	r.player = domain.NewReplayPlayer(r.Replay)
+
+	// draw the game being played back with the level scene
+	r.level = &LevelScene{Game: r.player.Game()}
+	r.level.Initialize()
+
+	r.level.Mutex.Lock()
+	defer r.level.Mutex.Unlock()
+
+	// replace the game controls with replay controls
+	r.level.background.RemoveAllTouchListener()
+	r.level.background.AddTouchListener(&replayTouchListener{parent: r})
+
+	// replays are silent, show the speed in place of the audio button
+	r.level.audioSprite.RemoveAllTouchListener()
+	simra.GetInstance().RemoveSprite(r.level.audioSprite)
+	r.level.audioSprite = nil
+	r.initSpeedSprites()
+
+	// keys control playback rather than the game being played back
+	listenForKeys(r)
+}
+
+func (r *ReplayScene) Destroy() {
+	stopListeningForKeys(r)
+	r.level.Destroy()
+	r.speedDigits = nil
+	r.speedPoint = nil
+}
+
+// initSpeedSprites shows the playback speed at the bottom right of the screen
+func (r *ReplayScene) initSpeedSprites() {
+
+	digitWidth := domain.BlockPixels / 2
+	x := float32(config.ScreenWidth - domain.AudioButtonWidth - 2*digitWidth)
+	y := float32(domain.AudioButtonHeight)
+
+	r.speedDigits = make([]*simra.Sprite, 0, 3)
+	for i := 0; i < 3; i++ {
+		digit := &simra.Sprite{}
+		digit.W = float32(digitWidth)
+		digit.H = float32(domain.BlockPixels)
+		digit.X = x
+		digit.Y = y
+
+		simra.GetInstance().AddSprite("digits.png",
+			image.Rect(0, 0, domain.DigitsWidth, domain.DigitsHeight),
+			digit)
+		r.speedDigits = append(r.speedDigits, digit)
+		x += float32(digitWidth)
+
+		if i > 0 {
+			continue
+		}
+		r.speedPoint = &simra.Sprite{}
+		r.speedPoint.W = float32(domain.TimerSeparatorWidth)
+		r.speedPoint.H = float32(domain.BlockPixels)
+		r.speedPoint.X = x - float32(domain.TimerSeparatorWidth)
+		r.speedPoint.Y = y
+
+		simra.GetInstance().AddSprite("point.png",
+			image.Rect(0, 0, 15, 40),
+			r.speedPoint)
+		x += float32(domain.TimerSeparatorWidth)
+	}
+	r.updateSpeedSprites()
+}
+
+func (r *ReplayScene) updateSpeedSprites() {
+	for i, value := range speedToDigits(r.player.Speed()) {
+		if i >= len(r.speedDigits) || r.speedDigits[i] == nil {
+			continue
+		}
+		peer.GetSpriteContainer().ReplaceTexture(&r.speedDigits[i].Sprite, *r.level.digitTextures[value])
+	}
+}
+
+// togglePause pauses and resumes playback, once the replay has ended it goes back to the menu
+func (r *ReplayScene) togglePause() {
+	if r.player.Ended() {
+		r.exit()
+		return
+	}
+	r.player.SetPaused(!r.player.Paused())
+}
+
+// seek skips forwards or backwards through the replay
+func (r *ReplayScene) seek(direction int) {
+	r.player.Seek(r.player.Frame() + direction*domain.SeekFrames)
+}
+
+// changeSpeed steps through the playback speeds
+func (r *ReplayScene) changeSpeed(steps int) {
+	r.player.SetSpeed(stepSpeed(r.player.Speed(), steps))
+	r.updateSpeedSprites()
+}
+
+func (r *ReplayScene) exit() {
+	simra.GetInstance().SetScene(&ModeScene{Game: r.Game})
+}
+
+// OnKeyDown controls playback on desktop
+func (r *ReplayScene) OnKeyDown(code key.Code) {
+	switch code {
+	case key.CodeSpacebar:
+		r.togglePause()
+	case key.CodeLeftArrow:
+		r.seek(-1)
+	case key.CodeRightArrow:
+		r.seek(1)
+	case key.CodeUpArrow:
+		r.changeSpeed(1)
+	case key.CodeDownArrow:
+		r.changeSpeed(-1)
+	case key.CodeEscape:
+		r.exit()
+	}
+}
+
+// Drive plays back the replay in real time and draws the game
+func (r *ReplayScene) Drive() {
+	now := time.Now()
+	if !r.lastDrive.IsZero() {
+		r.player.Tick(now.Sub(r.lastDrive))
+	}
+	r.lastDrive = now
+
+	r.level.draw()
+
+	// blink the speed while paused
+	r.frame++
+	width := float32(domain.BlockPixels / 2)
+	if r.player.Paused() && r.frame/domain.CursorBlinkFrames%2 == 1 {
+		width = 0
+	}
+	for _, digit := range r.speedDigits {
+		digit.W = width
+	}
+}
+
+// replayTouchListener controls playback
+type replayTouchListener struct {
+	parent         *ReplayScene
+	touchBeginX    float32
+	touchBeginY    float32
+	touchBeginTime time.Time
+}
+
+func (t *replayTouchListener) OnTouchBegin(x, y float32) {
+	t.touchBeginX = x
+	t.touchBeginY = y
+	t.touchBeginTime = time.Now()
+}
+
+func (t *replayTouchListener) OnTouchMove(x, y float32) {
+}
+
+func (t *replayTouchListener) OnTouchEnd(x, y float32) {
+	xMovement := x - t.touchBeginX
+	yMovement := y - t.touchBeginY
+
+	switch {
+	case xMovement <= -domain.FlickPixels:
+		t.parent.seek(-1)
+	case xMovement >= domain.FlickPixels:
+		t.parent.seek(1)
+	case yMovement >= domain.FlickPixels:
+		t.parent.changeSpeed(1)
+	case yMovement <= -domain.FlickPixels:
+		t.parent.changeSpeed(-1)
+	case time.Now().Sub(t.touchBeginTime) >= domain.QuitDuration*time.Millisecond:
+		// hold still to leave the replay
+		t.parent.exit()
+	default:
+		t.parent.togglePause()
+	}
+}
+
+// speedToDigits converts a playback speed to digit image indexes
+// for units, tenths and hundredths
+func speedToDigits(speed float64) []int {
+	hundredths := int(speed*100 + 0.5)
+	if hundredths > 999 {
+		hundredths = 999
+	}
+	return []int{hundredths / 100, hundredths / 10 % 10, hundredths % 10}
+}
+
+// stepSpeed returns the playback speed a number of steps faster or slower
+func stepSpeed(speed float64, steps int) float64 {
+	index := 0
+	for i, replaySpeed := range replaySpeeds {
+		if replaySpeed <= speed {
+			index = i
+		}
+	}
+	index += steps
+	if index < 0 {
+		index = 0
+	}
+	if index > len(replaySpeeds)-1 {
+		index = len(replaySpeeds) - 1
+	}
+	return replaySpeeds[index]
+}
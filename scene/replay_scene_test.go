@@ -0,0 +1,36 @@
+package scene
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpeedToDigits(t *testing.T) {
+
+	cases := map[float64][]int{
+		0.25: {0, 2, 5},
+		1:    {1, 0, 0},
+		8:    {8, 0, 0},
+		20:   {9, 9, 9},
+	}
+
+	for speed, expected := range cases {
+		digits := speedToDigits(speed)
+		if !reflect.DeepEqual(digits, expected) {
+			t.Errorf("Speed digits for %v incorrect Expected: %d got: %d", speed, expected, digits)
+		}
+	}
+}
+
+func TestStepSpeed(t *testing.T) {
+
+	if speed := stepSpeed(1, 1); speed != 2 {
+		t.Errorf("Expected speed: %v got: %v", 2.0, speed)
+	}
+	if speed := stepSpeed(0.25, -1); speed != 0.25 {
+		t.Errorf("Expected slowest speed: %v got: %v", 0.25, speed)
+	}
+	if speed := stepSpeed(8, 3); speed != 8 {
+		t.Errorf("Expected fastest speed: %v got: %v", 8.0, speed)
+	}
+}
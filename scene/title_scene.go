@@ -77,6 +77,6 @@ func (t *TitleScene) OnTouchMove(x, y float32) {
 }
 
 func (t *TitleScene) OnTouchEnd(x, y float32) {
-	// scene end. go to next scene
-	simra.GetInstance().SetScene(&IntroScene{Game: t.Game})
+	// scene end. pick a game mode next
+	simra.GetInstance().SetScene(&ModeScene{Game: t.Game})
 }